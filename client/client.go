@@ -18,6 +18,37 @@ const (
 	TypeUnknown Type = iota
 	TypeNormal
 	TypeDock
+	TypeDialog
+	TypeSplash
+	TypeUtility
+	TypeToolbar
+	TypeDesktop
+	TypeNotification
+)
+
+// Floating reports whether windows of this type are floating instead of tiled by default
+func (t Type) Floating() bool {
+	switch t {
+	case TypeDialog, TypeSplash, TypeUtility, TypeToolbar, TypeNotification:
+		return true
+	}
+	return false
+}
+
+// Decorated reports whether windows of this type are reparented into a frame with a titlebar
+func (t Type) Decorated() bool {
+	switch t {
+	case TypeNormal, TypeDialog, TypeUtility, TypeToolbar:
+		return true
+	}
+	return false
+}
+
+// Values of the WM_STATE property as defined by ICCCM
+const (
+	WithdrawnState = 0
+	NormalState    = 1
+	IconicState    = 3
 )
 
 type Client struct {
@@ -26,17 +57,34 @@ type Client struct {
 	parent xproto.Window
 	mapped bool
 
+	// pendingUnmaps counts the UnmapNotify events caused by the WM itself, which are not a sign
+	// of the client withdrawing its window
+	pendingUnmaps int
+
 	geom Geom
 	cfg  *Config
 	typ  Type
 
-	title string
+	title        string
+	hideTitlebar bool
+	hints        SizeHints
+
+	urgent           bool // the urgency hint of WM_HINTS
+	demandsAttention bool // _NET_WM_STATE_DEMANDS_ATTENTION
+	focused          bool
 }
 
 func New(x11 x11, cfg *Config, window xproto.Window, typ Type) (*Client, error) {
 	c := &Client{x11: x11, cfg: cfg, window: window, typ: typ}
+	c.updateSizeHints()
+	c.updateUrgency()
 
-	if typ == TypeNormal {
+	if typ.Decorated() {
+		// reparenting a mapped window unmaps it and maps it again
+		if x11.WindowMapped(window) {
+			c.mapped = true
+			c.pendingUnmaps++
+		}
 		parent, err := c.createParent()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create parent: %w", err)
@@ -55,8 +103,17 @@ func (c *Client) Window() xproto.Window { return c.window }
 func (c *Client) Parent() xproto.Window { return c.parent }
 func (c *Client) Geom() Geom            { return c.geom }
 func (c *Client) Mapped() bool          { return c.mapped }
+func (c *Client) SizeHints() SizeHints  { return c.hints }
 func (c *Client) SetGeom(geom Geom)     { c.geom = geom }
 
+// SetTitlebar shows or hides the titlebar drawn on the client's parent window
+func (c *Client) SetTitlebar(visible bool) { c.hideTitlebar = !visible }
+
+// HasTitlebar reports whether a titlebar is drawn above the client window
+func (c *Client) HasTitlebar() bool {
+	return c.parent != 0 && !c.hideTitlebar && c.cfg.TitlebarHeight > 0
+}
+
 func (c *Client) Draw() error {
 	return c.drawTitlebar()
 }
@@ -78,16 +135,26 @@ func (c *Client) Map() error {
 		return fmt.Errorf("could not map window: %w", err)
 	}
 	c.mapped = true
-	return nil
+	return c.x11.SetWMState(c.window, NormalState)
 }
 
-// Unmap causes the client window to be unmapped. This in turn sends the UnmapNotify event
-// that is then handled by (*Client).OnUnmap
+// Unmap causes both the client window and the frame (parent) to be unmapped, putting the client
+// in the iconic state. The resulting UnmapNotify event is ignored by (*Client).OnUnmap
 func (c *Client) Unmap() error {
-	if err := c.x11.UnmapWindow(c.window); err != nil {
-		return fmt.Errorf("could not unmap window: %w", err)
+	if c.mapped {
+		c.pendingUnmaps++
+		if err := c.x11.UnmapWindow(c.window); err != nil {
+			c.pendingUnmaps--
+			return fmt.Errorf("could not unmap window: %w", err)
+		}
+		c.mapped = false
 	}
-	return nil
+	if c.parent != 0 {
+		if err := c.x11.UnmapWindow(c.parent); err != nil {
+			return fmt.Errorf("could not unmap parent: %w", err)
+		}
+	}
+	return c.x11.SetWMState(c.window, IconicState)
 }
 
 // OnDestroy is called when the WM receives the DestroyNotify event
@@ -100,28 +167,71 @@ func (c *Client) OnDestroy() error {
 	return nil
 }
 
-// OnUnmap is called when the WM receives the UnmapNotify event (e.g. when the client window
-// is closed by user action or when requested by the program itself)
-func (c *Client) OnUnmap() error {
-	if !c.mapped {
-		return nil
+// OnUnmap is called when the WM receives the UnmapNotify event. Unless the event was caused by the WM,
+// the client has withdrawn its window (e.g. it was closed by user action or hidden by the program itself):
+// the window is then reparented back to the root window, put in the withdrawn state, and true is returned
+func (c *Client) OnUnmap() (bool, error) {
+	if c.pendingUnmaps > 0 {
+		c.pendingUnmaps--
+		return false, nil
 	}
+	c.mapped = false
 	if c.parent != 0 {
-		if err := c.x11.UnmapWindow(c.parent); err != nil {
-			return fmt.Errorf("could not unmap parent: %w", err)
+		// the window might already be destroyed, in which case reparenting fails but the parent still has to go
+		_ = c.x11.ReparentWindow(c.window, c.x11.GetRootWindow(), c.geom.X, c.geom.Y)
+		if err := c.x11.DestroyWindow(c.parent); err != nil {
+			return true, fmt.Errorf("could not destroy parent: %w", err)
 		}
+		c.parent = 0
 	}
-	c.mapped = false
-	return nil
+	_ = c.x11.SetWMState(c.window, WithdrawnState)
+	return true, nil
 }
 
 func (c *Client) OnProperty(atom xproto.Atom) {
 	switch atom {
 	case c.x11.Atom("_NET_WM_NAME"):
 		c.updateTitleProperty()
+	case c.x11.Atom("WM_NORMAL_HINTS"):
+		c.updateSizeHints()
+	case c.x11.Atom("WM_HINTS"):
+		if c.updateUrgency() {
+			_ = c.Draw()
+		}
+	}
+}
+
+// Urgent reports whether the client needs the user's attention, either with the urgency hint
+// or the _NET_WM_STATE_DEMANDS_ATTENTION state
+func (c *Client) Urgent() bool { return c.urgent || c.demandsAttention }
+
+// DemandsAttention reports whether the client is in the _NET_WM_STATE_DEMANDS_ATTENTION state
+func (c *Client) DemandsAttention() bool { return c.demandsAttention }
+
+// SetDemandsAttention changes the _NET_WM_STATE_DEMANDS_ATTENTION state of the client
+func (c *Client) SetDemandsAttention(demands bool) {
+	if demands != c.demandsAttention {
+		c.demandsAttention = demands
+		_ = c.Draw()
+	}
+}
+
+// SetFocused tells the client whether its window has the input focus. The title bar of the focused window
+// is never drawn as urgent, even if the client hasn't reset its urgency hint yet
+func (c *Client) SetFocused(focused bool) {
+	if focused != c.focused {
+		c.focused = focused
+		if c.Urgent() {
+			_ = c.Draw()
+		}
 	}
 }
 
+// showUrgent reports whether the title bar is drawn in the urgent colour
+func (c *Client) showUrgent() bool {
+	return c.Urgent() && !c.focused
+}
+
 // createParent generates an X window and sets it up so that it can be used for reparenting
 func (c *Client) createParent() (xproto.Window, error) {
 	return c.x11.CreateWindow(c.x11.GetRootWindow(),
@@ -140,6 +250,10 @@ func (c *Client) createParent() (xproto.Window, error) {
 }
 
 func (c *Client) reparent(parent xproto.Window) error {
+	// keep the window alive when the WM exits or restarts
+	if err := c.x11.AddToSaveSet(c.window); err != nil {
+		return fmt.Errorf("could not add window to save-set: %w", err)
+	}
 	if err := c.x11.ReparentWindow(c.window, parent, 0, 0); err != nil {
 		return fmt.Errorf("could not reparent window: %w", err)
 	}
@@ -155,3 +269,21 @@ func (c *Client) updateTitleProperty() {
 		}
 	}
 }
+
+func (c *Client) updateSizeHints() {
+	if vals, err := c.x11.GetNormalHints(c.window); err == nil {
+		c.hints = parseSizeHints(vals)
+	}
+}
+
+// updateUrgency reads the urgency hint from WM_HINTS, returning true if it changed
+func (c *Client) updateUrgency() bool {
+	const urgencyHint = 1 << 8
+	urgent := false
+	if vals, err := c.x11.GetWMHints(c.window); err == nil && len(vals) > 0 {
+		urgent = vals[0]&urgencyHint != 0
+	}
+	changed := urgent != c.urgent
+	c.urgent = urgent
+	return changed
+}
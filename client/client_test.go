@@ -27,4 +27,48 @@ func TestNew(t *testing.T) {
 			t.Errorf("got = %v, want = %v", got, want)
 		}
 	})
+	t.Run("TypeSplash", func(t *testing.T) {
+		x11 := &mockX11{t: t}
+		cfg := &Config{}
+		c, err := New(x11, cfg, window, TypeSplash)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if len(x11.reparentedWins) != 0 {
+			t.Errorf("expected the window not to be reparented")
+		}
+		if c.Parent() != 0 {
+			t.Errorf("got parent = %v, want = 0", c.Parent())
+		}
+	})
+}
+
+func TestUrgency(t *testing.T) {
+	window := xproto.Window(50)
+	tests := []struct {
+		name    string
+		wmHints []uint32
+		want    bool
+	}{
+		{"NoHints", nil, false},
+		{"InputHint", []uint32{1, 1}, false},
+		{"UrgencyHint", []uint32{1<<8 | 1, 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x11 := &mockX11{t: t, wmHints: tt.wmHints}
+			c, err := New(x11, &Config{}, window, TypeSplash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.Urgent(); got != tt.want {
+				t.Errorf("got urgent = %v, want = %v", got, tt.want)
+			}
+			c.SetFocused(true)
+			if c.showUrgent() {
+				t.Errorf("focused client shown as urgent")
+			}
+		})
+	}
 }
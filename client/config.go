@@ -4,6 +4,7 @@ type Config struct {
 	TitlebarHeight uint8
 	BorderWidth    uint8
 	BgColor        uint32
+	UrgentBgColor  uint32
 	FontColor      uint32
 	FontSize       float64
 }
@@ -0,0 +1,107 @@
+package client
+
+// Flags of the WM_SIZE_HINTS structure as defined by ICCCM
+const (
+	hintPMinSize   = 1 << 4
+	hintPMaxSize   = 1 << 5
+	hintPResizeInc = 1 << 6
+	hintPAspect    = 1 << 7
+	hintPBaseSize  = 1 << 8
+)
+
+// SizeHints are the size constraints of a client, as read from its WM_NORMAL_HINTS property
+type SizeHints struct {
+	MinW, MinH     uint16
+	MaxW, MaxH     uint16
+	IncW, IncH     uint16
+	BaseW, BaseH   uint16
+	MinAspect      float64 // minimum width/height ratio
+	MaxAspect      float64 // maximum width/height ratio
+	hasBase        bool
+	hasMin, hasMax bool
+}
+
+// parseSizeHints decodes the values of the WM_SIZE_HINTS property. Missing fields are left zeroed
+func parseSizeHints(vals []uint32) SizeHints {
+	var h SizeHints
+	if len(vals) < 15 {
+		return h
+	}
+	flags := vals[0]
+	if flags&hintPMinSize != 0 {
+		h.MinW, h.MinH = uint16(vals[5]), uint16(vals[6])
+		h.hasMin = true
+	}
+	if flags&hintPMaxSize != 0 {
+		h.MaxW, h.MaxH = uint16(vals[7]), uint16(vals[8])
+		h.hasMax = true
+	}
+	if flags&hintPResizeInc != 0 {
+		h.IncW, h.IncH = uint16(vals[9]), uint16(vals[10])
+	}
+	if flags&hintPAspect != 0 && vals[12] != 0 && vals[14] != 0 {
+		h.MinAspect = float64(vals[11]) / float64(vals[12])
+		h.MaxAspect = float64(vals[13]) / float64(vals[14])
+	}
+	if flags&hintPBaseSize != 0 && len(vals) >= 17 {
+		h.BaseW, h.BaseH = uint16(vals[15]), uint16(vals[16])
+		h.hasBase = true
+	}
+	// ICCCM: base size defaults to the minimum size and vice versa
+	switch {
+	case !h.hasBase && h.hasMin:
+		h.BaseW, h.BaseH = h.MinW, h.MinH
+	case !h.hasMin && h.hasBase:
+		h.MinW, h.MinH = h.BaseW, h.BaseH
+	}
+	return h
+}
+
+// Fixed reports whether the client cannot be resized, i.e. its minimum and maximum sizes are equal
+func (h SizeHints) Fixed() bool {
+	return h.hasMin && h.hasMax && h.MinW > 0 && h.MinH > 0 && h.MinW == h.MaxW && h.MinH == h.MaxH
+}
+
+// Constrain returns the largest size fitting within the given width and height that satisfies the hints
+func (h SizeHints) Constrain(width, height uint16) (uint16, uint16) {
+	w, ht := int(width), int(height)
+	baseW, baseH := int(h.BaseW), int(h.BaseH)
+
+	// the aspect ratio applies to the size without the base
+	w -= baseW
+	ht -= baseH
+	if h.MaxAspect > 0 && ht > 0 && float64(w)/float64(ht) > h.MaxAspect {
+		w = int(float64(ht)*h.MaxAspect + 0.5)
+	} else if h.MinAspect > 0 && w > 0 && float64(w)/float64(ht) < h.MinAspect {
+		ht = int(float64(w)/h.MinAspect + 0.5)
+	}
+	if h.IncW > 1 && w > 0 {
+		w -= w % int(h.IncW)
+	}
+	if h.IncH > 1 && ht > 0 {
+		ht -= ht % int(h.IncH)
+	}
+	w += baseW
+	ht += baseH
+
+	if w < int(h.MinW) {
+		w = int(h.MinW)
+	}
+	if ht < int(h.MinH) {
+		ht = int(h.MinH)
+	}
+	if h.MaxW > 0 && w > int(h.MaxW) {
+		w = int(h.MaxW)
+	}
+	if h.MaxH > 0 && ht > int(h.MaxH) {
+		ht = int(h.MaxH)
+	}
+	// never exceed the available space, the client would be clipped by its frame anyway
+	if w > int(width) || w <= 0 {
+		w = int(width)
+	}
+	if ht > int(height) || ht <= 0 {
+		ht = int(height)
+	}
+	return uint16(w), uint16(ht)
+}
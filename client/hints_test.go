@@ -0,0 +1,76 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestSizeHintsConstrain(t *testing.T) {
+	tests := []struct {
+		name  string
+		vals  []uint32
+		w, h  uint16
+		wantW uint16
+		wantH uint16
+	}{
+		{
+			name:  "no hints",
+			vals:  nil,
+			w:     800,
+			h:     600,
+			wantW: 800,
+			wantH: 600,
+		},
+		{
+			name: "resize increments with base size",
+			// flags, pad x4, min, max, inc, min aspect, max aspect, base
+			vals:  []uint32{hintPResizeInc | hintPBaseSize, 0, 0, 0, 0, 0, 0, 0, 0, 7, 15, 0, 0, 0, 0, 4, 2},
+			w:     800,
+			h:     600,
+			wantW: 795,
+			wantH: 587,
+		},
+		{
+			name:  "maximum size",
+			vals:  []uint32{hintPMaxSize, 0, 0, 0, 0, 0, 0, 400, 300, 0, 0, 0, 0, 0, 0},
+			w:     800,
+			h:     600,
+			wantW: 400,
+			wantH: 300,
+		},
+		{
+			name:  "minimum size larger than available",
+			vals:  []uint32{hintPMinSize, 0, 0, 0, 0, 1000, 100, 0, 0, 0, 0, 0, 0, 0, 0},
+			w:     800,
+			h:     600,
+			wantW: 800,
+			wantH: 600,
+		},
+		{
+			name:  "aspect ratio",
+			vals:  []uint32{hintPAspect, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 3, 4, 3},
+			w:     800,
+			h:     300,
+			wantW: 400,
+			wantH: 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := parseSizeHints(tt.vals).Constrain(tt.w, tt.h)
+			if w != tt.wantW || h != tt.wantH {
+				t.Errorf("got = %dx%d, want = %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestSizeHintsFixed(t *testing.T) {
+	fixed := parseSizeHints([]uint32{hintPMinSize | hintPMaxSize, 0, 0, 0, 0, 300, 200, 300, 200, 0, 0, 0, 0, 0, 0})
+	if !fixed.Fixed() {
+		t.Errorf("expected the hints to be fixed")
+	}
+	resizable := parseSizeHints([]uint32{hintPMinSize | hintPMaxSize, 0, 0, 0, 0, 300, 200, 600, 400, 0, 0, 0, 0, 0, 0})
+	if resizable.Fixed() {
+		t.Errorf("expected the hints not to be fixed")
+	}
+}
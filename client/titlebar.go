@@ -11,12 +11,20 @@ import (
 )
 
 func (c *Client) drawTitlebar() error {
+	// nothing to draw until the client has been given its geometry
+	if !c.HasTitlebar() || c.geom.W == 0 {
+		return nil
+	}
 	width := c.geom.W
+	bgColor := c.cfg.BgColor
+	if c.showUrgent() && c.cfg.UrgentBgColor != 0 {
+		bgColor = c.cfg.UrgentBgColor
+	}
 	bg := color.RGBA{
-		A: uint8((c.cfg.BgColor & 0xFF000000) >> 24),
-		R: uint8((c.cfg.BgColor & 0x00FF0000) >> 16),
-		G: uint8((c.cfg.BgColor & 0x0000FF00) >> 8),
-		B: uint8(c.cfg.BgColor & 0x000000FF),
+		A: uint8((bgColor & 0xFF000000) >> 24),
+		R: uint8((bgColor & 0x00FF0000) >> 16),
+		G: uint8((bgColor & 0x0000FF00) >> 8),
+		B: uint8(bgColor & 0x000000FF),
 	}
 	fg := color.RGBA{
 		A: uint8((c.cfg.FontColor & 0xFF000000) >> 24),
@@ -16,12 +16,17 @@ type x11 interface {
 		class uint16, valueMask uint32, valueList []uint32,
 	) (xproto.Window, error)
 
+	WindowMapped(window xproto.Window) bool
 	MapWindow(window xproto.Window) error
 	UnmapWindow(window xproto.Window) error
 	DestroyWindow(window xproto.Window) error
 	ReparentWindow(window, parent xproto.Window, x, y int16) error
+	AddToSaveSet(window xproto.Window) error
 
 	GetWindowTitle(window xproto.Window) (string, error)
+	GetNormalHints(window xproto.Window) ([]uint32, error)
+	GetWMHints(window xproto.Window) ([]uint32, error)
+	SetWMState(window xproto.Window, state uint32) error
 	Atom(name string) xproto.Atom
 
 	NewImage(rect image.Rectangle) *xgraphics.Image
@@ -20,6 +20,7 @@ type mockX11 struct {
 	// unmappedWins   []xproto.Window
 	// destroyedWins  []xproto.Window
 	reparentedWins []mockReparented
+	wmHints        []uint32
 }
 
 func (mx *mockX11) GetRootWindow() xproto.Window {
@@ -34,6 +35,9 @@ func (mx *mockX11) CreateWindow(
 	return 1, nil
 }
 
+func (mx *mockX11) WindowMapped(window xproto.Window) bool {
+	return false
+}
 func (mx *mockX11) MapWindow(window xproto.Window) error {
 	return nil
 }
@@ -52,10 +56,22 @@ func (mx *mockX11) ReparentWindow(window, parent xproto.Window, x, y int16) erro
 	})
 	return nil
 }
+func (mx *mockX11) AddToSaveSet(window xproto.Window) error {
+	return nil
+}
 
 func (mx *mockX11) GetWindowTitle(window xproto.Window) (string, error) {
 	return "", nil
 }
+func (mx *mockX11) GetNormalHints(window xproto.Window) ([]uint32, error) {
+	return nil, nil
+}
+func (mx *mockX11) GetWMHints(window xproto.Window) ([]uint32, error) {
+	return mx.wmHints, nil
+}
+func (mx *mockX11) SetWMState(window xproto.Window, state uint32) error {
+	return nil
+}
 func (mx *mockX11) Atom(name string) xproto.Atom {
 	return 0
 }
@@ -21,11 +21,13 @@ var (
 var (
 	flagVersion bool
 	initCmd     string
+	command     string
 )
 
 func main() {
 	flag.BoolVar(&flagVersion, "version", false, "show version and exit")
 	flag.StringVar(&initCmd, "init", "", "run this executable at startup")
+	flag.StringVarP(&command, "command", "c", "", "send a command to the running window manager and exit")
 	flag.Parse()
 
 	if flagVersion {
@@ -34,6 +36,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	if command != "" {
+		if err := wm.SendCommand(command); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
 	mgr, err := wm.New(marwind.Config)
 	if err != nil {
 		log.Fatal(err)
@@ -43,7 +52,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if initCmd != "" {
+	if initCmd != "" && !mgr.Restarted() {
 		cmd := exec.Command(initCmd)
 		err = cmd.Start()
 		if err != nil {
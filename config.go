@@ -12,10 +12,14 @@ var Config = wm.Config{
 	Shell:                   "/bin/sh",
 	LauncherCommand:         "rofi -show drun",
 	TerminalCommand:         "alacritty",
+	PickerCommand:           "rofi -dmenu -i -p hidden",
+	FocusModel:              wm.FocusSloppy,
+	ScrollColumnWidth:       0.5,
 	BorderWidth:             0,
 	BorderColor:             0xffa1d1cf,
 	TitleBarHeight:          18,
 	TitleBarBgColor:         0xffa1d1cf,
+	TitleBarBgColorUrgent:   0xffe06c75,
 	TitleBarFontColorActive: 0xff000000,
 	TitleBarFontSize:        12,
 	Keybindings: map[xproto.Keysym]string{
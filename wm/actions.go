@@ -1,9 +1,11 @@
 package wm
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
 
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/keysym"
@@ -19,6 +21,8 @@ type action struct {
 func initActions(wm *WM) []*action {
 	mod := xproto.ModMask4
 	shift := xproto.ModMaskShift
+	ctrl := xproto.ModMaskControl
+	alt := xproto.ModMask1
 	actions := []*action{
 		{
 			sym:       keysym.XKq,
@@ -29,12 +33,19 @@ func initActions(wm *WM) []*action {
 		},
 		{
 			sym:       keysym.XKt,
-			modifiers: mod | shift | xproto.ModMask1,
+			modifiers: mod | shift | alt,
 			act: func() error {
 				os.Exit(1)
 				return nil
 			},
 		},
+		{
+			sym:       keysym.XKr,
+			modifiers: mod | shift,
+			act: func() error {
+				return wm.restart()
+			},
+		},
 		{
 			sym:       keysym.XKd,
 			modifiers: mod,
@@ -61,6 +72,26 @@ func initActions(wm *WM) []*action {
 				return nil
 			},
 		},
+		{
+			sym:       keysym.XKh,
+			modifiers: mod,
+			act:       func() error { return handleFocusDirection(wm, MoveLeft) },
+		},
+		{
+			sym:       keysym.XKj,
+			modifiers: mod,
+			act:       func() error { return handleFocusDirection(wm, MoveDown) },
+		},
+		{
+			sym:       keysym.XKk,
+			modifiers: mod,
+			act:       func() error { return handleFocusDirection(wm, MoveUp) },
+		},
+		{
+			sym:       keysym.XKl,
+			modifiers: mod,
+			act:       func() error { return handleFocusDirection(wm, MoveRight) },
+		},
 		{
 			sym:       keysym.XKh,
 			modifiers: mod | shift,
@@ -81,6 +112,41 @@ func initActions(wm *WM) []*action {
 			modifiers: mod | shift,
 			act:       func() error { return handleMoveWindow(wm, MoveRight) },
 		},
+		{
+			sym:       keysym.XKh,
+			modifiers: mod | alt,
+			act:       func() error { return handleMoveColumn(wm, MoveLeft) },
+		},
+		{
+			sym:       keysym.XKl,
+			modifiers: mod | alt,
+			act:       func() error { return handleMoveColumn(wm, MoveRight) },
+		},
+		{
+			sym:       keysym.XKh,
+			modifiers: mod | alt | shift,
+			act:       func() error { return handleMoveWindowToNewColumn(wm, MoveLeft) },
+		},
+		{
+			sym:       keysym.XKl,
+			modifiers: mod | alt | shift,
+			act:       func() error { return handleMoveWindowToNewColumn(wm, MoveRight) },
+		},
+		{
+			sym:       keysym.XKs,
+			modifiers: mod,
+			act:       func() error { return handleScrollWorkspace(wm, "toggle") },
+		},
+		{
+			sym:       keysym.XKb,
+			modifiers: mod,
+			act:       func() error { return handleSplitWindow(wm, true) },
+		},
+		{
+			sym:       keysym.XKv,
+			modifiers: mod,
+			act:       func() error { return handleSplitWindow(wm, false) },
+		},
 		{
 			sym:       keysym.XKy,
 			modifiers: mod | shift,
@@ -101,6 +167,61 @@ func initActions(wm *WM) []*action {
 			modifiers: mod | shift,
 			act:       func() error { return handleResizeWindow(wm, ResizeHoriz, 5) },
 		},
+		{
+			sym:       keysym.XKMinus,
+			modifiers: mod | shift,
+			act:       func() error { return handleMoveWindowToScratchpad(wm) },
+		},
+		{
+			sym:       keysym.XKMinus,
+			modifiers: mod,
+			act:       func() error { return wm.toggleScratchpad() },
+		},
+		{
+			sym:       keysym.XKn,
+			modifiers: mod,
+			act:       func() error { return handleHideWindow(wm) },
+		},
+		{
+			sym:       keysym.XKn,
+			modifiers: mod | shift,
+			act:       func() error { return wm.restoreHidden(0) },
+		},
+		{
+			sym:       keysym.XKn,
+			modifiers: mod | ctrl,
+			act:       func() error { return wm.pickHidden() },
+		},
+		{
+			sym:       keysym.XKu,
+			modifiers: mod,
+			act:       func() error { return wm.focusUrgent() },
+		},
+		{
+			sym:       keysym.XKTab,
+			modifiers: mod,
+			act:       func() error { return wm.switchWorkspaceBackAndForth() },
+		},
+		{
+			sym:       keysym.XKBracketLeft,
+			modifiers: mod,
+			act:       func() error { return wm.switchWorkspaceRelative(-1, false) },
+		},
+		{
+			sym:       keysym.XKBracketRight,
+			modifiers: mod,
+			act:       func() error { return wm.switchWorkspaceRelative(1, false) },
+		},
+		{
+			sym:       keysym.XKh,
+			modifiers: mod | ctrl,
+			act:       func() error { return handleMoveWorkspaceToOutput(wm, "left") },
+		},
+		{
+			sym:       keysym.XKl,
+			modifiers: mod | ctrl,
+			act:       func() error { return handleMoveWorkspaceToOutput(wm, "right") },
+		},
 	}
 	actions = appendWorkspaceActions(wm, actions, mod, mod|shift)
 
@@ -133,25 +254,25 @@ func initActions(wm *WM) []*action {
 }
 
 func appendWorkspaceActions(wm *WM, actions []*action, switchMod int, moveMod int) []*action {
-	for i := 0; i < maxWorkspaces; i++ {
+	for i := 0; i < 10; i++ {
 		var sym xproto.Keysym
 		if i == 9 {
 			sym = keysym.XK0
 		} else {
 			sym = xproto.Keysym(keysym.XK1 + i)
 		}
-		wsID := i
+		name := strconv.Itoa(i + 1)
 		actions = append(actions, &action{
 			sym:       sym,
 			modifiers: switchMod,
 			act: func() error {
-				return handleSwitchWorkspace(wm, uint8(wsID))
+				return handleSwitchWorkspace(wm, name)
 			},
 		}, &action{
 			sym:       sym,
 			modifiers: moveMod,
 			act: func() error {
-				return handleMoveWindowToWorkspace(wm, uint8(wsID))
+				return handleMoveWindowToWorkspace(wm, name)
 			},
 		})
 	}
@@ -167,6 +288,19 @@ func handleRemoveWindow(wm *WM) error {
 	return wm.xc.GracefullyDestroyWindow(frm.cli.Window())
 }
 
+// handleFocusDirection focuses the neighbour of the focused frame in the given direction
+func handleFocusDirection(wm *WM, dir MoveDirection) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil || !frm.tiled() {
+		log.Printf("WARNING: handleFocusDirection: could not find tiled frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	if other := frm.workspace().frameInDirection(frm, dir); other != nil {
+		return wm.activateFrame(other, xproto.TimeCurrentTime)
+	}
+	return nil
+}
+
 func handleMoveWindow(wm *WM, dir MoveDirection) error {
 	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
 	if frm == nil {
@@ -182,6 +316,93 @@ func handleMoveWindow(wm *WM, dir MoveDirection) error {
 	return wm.warpPointerToFrame(frm)
 }
 
+// handleMoveColumn swaps the column of the focused frame with its neighbour
+func handleMoveColumn(wm *WM, dir MoveDirection) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil || !frm.tiled() {
+		log.Printf("WARNING: handleMoveColumn: could not find tiled frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	frm.workspace().moveColumn(frm.topColumn(), dir)
+	if err := wm.renderWorkspace(frm.workspace()); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(frm)
+}
+
+// handleMoveWindowToNewColumn puts the focused frame in a new column next to its current one
+func handleMoveWindowToNewColumn(wm *WM, dir MoveDirection) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil || !frm.tiled() {
+		log.Printf("WARNING: handleMoveWindowToNewColumn: could not find tiled frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	frm.workspace().moveFrameToNewColumn(frm, dir)
+	if err := wm.renderWorkspace(frm.workspace()); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(frm)
+}
+
+// handleSwapWindow swaps the focused frame with its neighbour in the given direction, or with the frame
+// of the given window on the same workspace
+func handleSwapWindow(wm *WM, target string) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil || !frm.tiled() {
+		log.Printf("WARNING: handleSwapWindow: could not find tiled frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	ws := frm.workspace()
+	var other *frame
+	if dir, ok := parseDirection(target); ok {
+		other = ws.frameInDirection(frm, dir)
+	} else {
+		win, err := strconv.ParseUint(target, 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid window %q", target)
+		}
+		other = wm.findFrame(func(f *frame) bool { return f.cli.Window() == xproto.Window(win) })
+		if other == nil || other.workspace() != ws || !other.tiled() {
+			return fmt.Errorf("no tiled window %q on the workspace %q", target, ws.name)
+		}
+	}
+	if other == nil {
+		return nil
+	}
+	ws.swapFrames(frm, other)
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(frm)
+}
+
+// handleScrollWorkspace turns the scrolling of the columns of the current workspace on, off, or toggles it
+func handleScrollWorkspace(wm *WM, mode string) error {
+	ws := wm.currentOutput().activeWs
+	switch mode {
+	case "on":
+		ws.setScrolling(true)
+	case "off":
+		ws.setScrolling(false)
+	case "toggle":
+		ws.setScrolling(!ws.scrolling)
+	default:
+		return fmt.Errorf("usage: workspace scroll on|off|toggle")
+	}
+	return wm.renderWorkspace(ws)
+}
+
+// handleSplitWindow makes the next new window of the workspace share the place of the focused frame
+func handleSplitWindow(wm *WM, horizontal bool) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil || !frm.tiled() {
+		log.Printf("WARNING: handleSplitWindow: could not find tiled frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	frm.workspace().splitFrame(frm, horizontal)
+	return nil
+}
+
 func handleResizeWindow(wm *WM, dir ResizeDirection, pct int) error {
 	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
 	if frm == nil {
@@ -197,18 +418,60 @@ func handleResizeWindow(wm *WM, dir ResizeDirection, pct int) error {
 	return wm.warpPointerToFrame(frm)
 }
 
-func handleSwitchWorkspace(wm *WM, wsID uint8) error {
-	return wm.switchWorkspace(wsID)
+// handleSwitchWorkspace shows the named workspace, or the previous one if the named workspace is already shown
+func handleSwitchWorkspace(wm *WM, name string) error {
+	if ws := wm.lookupWorkspace(name); ws != nil && ws.output != nil && ws == ws.output.activeWs {
+		return wm.switchWorkspaceBackAndForth()
+	}
+	return wm.switchWorkspace(name)
 }
 
-func handleMoveWindowToWorkspace(wm *WM, wsID uint8) error {
+func handleMoveWindowToWorkspace(wm *WM, name string) error {
 	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
 	if frm == nil {
 		log.Printf("WARNING: handleMoveWindowToWorkspace: could not find frame with window %d\n", wm.activeWin)
 		return nil
 	}
-	if err := wm.moveFrameToWorkspace(frm, wsID); err != nil {
+	if err := wm.moveFrameToWorkspace(frm, name); err != nil {
 		return err
 	}
 	return nil
 }
+
+// handleMoveWorkspaceToOutput moves the current workspace to the output to the left, to the right or with the given name
+func handleMoveWorkspaceToOutput(wm *WM, target string) error {
+	ws := wm.currentOutput().activeWs
+	var o *output
+	switch target {
+	case "left":
+		o = wm.outputInDirection(ws.output, MoveLeft)
+	case "right":
+		o = wm.outputInDirection(ws.output, MoveRight)
+	default:
+		if o = wm.findOutput(func(o *output) bool { return o.name == target }); o == nil {
+			return fmt.Errorf("no output named %q", target)
+		}
+	}
+	if o == nil {
+		return nil
+	}
+	return wm.moveWorkspaceToOutput(ws, o)
+}
+
+func handleHideWindow(wm *WM) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil {
+		log.Printf("WARNING: handleHideWindow: could not find frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	return wm.hideFrame(frm)
+}
+
+func handleMoveWindowToScratchpad(wm *WM) error {
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if frm == nil {
+		log.Printf("WARNING: handleMoveWindowToScratchpad: could not find frame with window %d\n", wm.activeWin)
+		return nil
+	}
+	return wm.moveFrameToScratchpad(frm)
+}
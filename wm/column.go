@@ -1,51 +1,135 @@
 package wm
 
+// node is an item of a column: either a frame or a nested container
+type node interface {
+	// share returns the weight of the item, relative to the other items of its container
+	share() *float64
+	// setContainer changes the container holding the item
+	setContainer(c *column)
+}
+
+// column is a container dividing its area between its items. The columns of a workspace stack their items,
+// while the containers nested in them can also place the items side by side, so that any frame can be split
 type column struct {
-	ws     *workspace
-	frames []*frame
-	width  uint16
+	ws         *workspace
+	parent     *column // the container this one is nested in, nil for the columns of the workspace
+	horizontal bool    // whether the items are placed side by side instead of stacked
+	nodes      []node
+	weight     float64 // share of the workspace width (or of the parent container), relative to the other items
 }
 
+func (f *frame) share() *float64         { return &f.weight }
+func (f *frame) setContainer(c *column)  { f.col = c }
+func (c *column) share() *float64        { return &c.weight }
+func (c *column) setContainer(p *column) { c.parent = p }
+
+// addFrame appends the frame to the column, or puts it right after the given frame if the column holds it
 func (c *column) addFrame(frm *frame, after *frame) {
-	frm.col = c
-	wsHeight := c.ws.area().H
-	if len(c.frames) > 0 {
-		frm.height = wsHeight / uint16(len(c.frames)+1)
-		remHeight := float32(wsHeight - frm.height)
-		leftHeight := uint16(remHeight)
-		for _, f := range c.frames {
-			f.height = uint16((float32(f.height) / float32(wsHeight)) * remHeight)
-			leftHeight -= f.height
-		}
-		if leftHeight != 0 {
-			frm.height += leftHeight
+	i := len(c.nodes)
+	if after != nil {
+		if j := c.index(after); j >= 0 {
+			i = j + 1
 		}
-	} else {
-		frm.height = wsHeight
 	}
-	c.frames = append(c.frames, frm)
+	c.insertNode(frm, i)
+}
+
+// insertFrame adds the frame to the column at the given index
+func (c *column) insertFrame(frm *frame, i int) {
+	c.insertNode(frm, i)
+}
+
+// insertNode adds the item to the container at the given index, taking an equal share of it
+func (c *column) insertNode(n node, i int) {
+	if i < 0 || i > len(c.nodes) {
+		i = len(c.nodes)
+	}
+	*n.share() = newWeight(c.weights())
+	n.setContainer(c)
+	c.nodes = append(c.nodes, nil)
+	copy(c.nodes[i+1:], c.nodes[i:])
+	c.nodes[i] = n
+	normalize(c.weights())
 }
 
-func (c *column) deleteFrame(frm *frame) {
-	idx := c.findFrameIndex(func(f *frame) bool { return f == frm })
-	if idx < 0 {
+// deleteNode removes the item from the container, scaling the other items to take its space
+func (c *column) deleteNode(n node) {
+	i := c.index(n)
+	if i < 0 {
 		return
 	}
-	c.frames = append(c.frames[:idx], c.frames[idx+1:]...)
-	c.updateTiling()
+	c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+	normalize(c.weights())
+}
+
+// replaceNode puts the item in place of another one, taking over its share of the container
+func (c *column) replaceNode(old, n node) {
+	i := c.index(old)
+	if i < 0 {
+		return
+	}
+	*n.share() = *old.share()
+	n.setContainer(c)
+	c.nodes[i] = n
+	c.absorb(n)
+}
+
+// absorb replaces a nested container placing its items the same way as this one with the items themselves,
+// scaled to the share of the nested container
+func (c *column) absorb(n node) {
+	sub, ok := n.(*column)
+	if !ok || sub.horizontal != c.horizontal {
+		return
+	}
+	i := c.index(sub)
+	if i < 0 {
+		return
+	}
+	nodes := make([]node, 0, len(c.nodes)+len(sub.nodes)-1)
+	nodes = append(nodes, c.nodes[:i]...)
+	for _, item := range sub.nodes {
+		*item.share() *= sub.weight
+		item.setContainer(c)
+		nodes = append(nodes, item)
+	}
+	c.nodes = append(nodes, c.nodes[i+1:]...)
+	normalize(c.weights())
+}
+
+// frames returns all the frames of the container, including the ones in the nested containers
+func (c *column) frames() []*frame {
+	var frames []*frame
+	for _, n := range c.nodes {
+		switch n := n.(type) {
+		case *frame:
+			frames = append(frames, n)
+		case *column:
+			frames = append(frames, n.frames()...)
+		}
+	}
+	return frames
+}
+
+// setFrameWeight changes the share of the column taken by the frame, scaling the other frames to fill the rest
+func (c *column) setFrameWeight(frm *frame, share float64) {
+	if i := c.index(frm); i >= 0 {
+		setShare(c.weights(), i, share)
+	}
 }
 
-func (c *column) updateTiling() {
-	wsHeight := c.ws.area().H
-	// TODO: assign the heights proportional to the original height/totalHeight ratio
-	for _, f := range c.frames {
-		f.height = wsHeight / uint16(len(c.frames))
+// weights returns the pointers to the weights of the items, in order
+func (c *column) weights() []*float64 {
+	weights := make([]*float64, len(c.nodes))
+	for i, n := range c.nodes {
+		weights[i] = n.share()
 	}
+	return weights
 }
 
-func (c *column) findFrameIndex(predicate func(*frame) bool) int {
-	for i, f := range c.frames {
-		if predicate(f) {
+// index returns the position of the item in the container, or -1 if the container doesn't hold it
+func (c *column) index(n node) int {
+	for i, item := range c.nodes {
+		if item == n {
 			return i
 		}
 	}
@@ -0,0 +1,212 @@
+package wm
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/x11"
+)
+
+// commandProp is the root window property through which other programs send commands to the WM.
+// Each command is a single line appended to the property
+const commandProp = "_MARWIND_COMMAND"
+
+type command func(wm *WM, args []string) error
+
+// commands maps the names of the commands to their handlers, receiving the rest of the command line as arguments
+var commands = map[string]command{
+	"restart":    func(wm *WM, args []string) error { return wm.restart() },
+	"session":    cmdSession,
+	"layout":     cmdLayout,
+	"scratchpad": cmdScratchpad,
+	"workspace":  cmdWorkspace,
+	"hidden":     cmdHidden,
+	"urgent":     cmdUrgent,
+	"column":     cmdColumn,
+	"window":     cmdWindow,
+	"focus":      cmdFocus,
+}
+
+// SendCommand passes the command line to the running instance of the WM
+func SendCommand(line string) error {
+	xc, err := x11.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %v", err)
+	}
+	defer xc.Close()
+	return xc.AppendRootProp(commandProp, []byte(line+"\n"))
+}
+
+// runCommand executes a single command line
+func (wm *WM) runCommand(line string) error {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd(wm, args[1:])
+}
+
+// handleCommandProp executes all the commands appended to the command property since it was last read
+func (wm *WM) handleCommandProp() {
+	data, err := wm.xc.PopRootProp(commandProp)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if err := wm.runCommand(line); err != nil {
+			log.Printf("Failed to run command %q: %v\n", line, err)
+		}
+	}
+}
+
+func cmdSession(wm *WM, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: session save|restore <name>")
+	}
+	switch args[0] {
+	case "save":
+		return wm.saveSession(args[1])
+	case "restore":
+		return wm.restoreSession(args[1])
+	}
+	return fmt.Errorf("unknown session subcommand %q", args[0])
+}
+
+func cmdLayout(wm *WM, args []string) error {
+	if len(args) != 2 || args[0] != "append" {
+		return fmt.Errorf("usage: layout append <file>")
+	}
+	return wm.appendLayout(wm.currentOutput().activeWs, args[1])
+}
+
+func cmdScratchpad(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: scratchpad move|show")
+	}
+	switch args[0] {
+	case "move":
+		return handleMoveWindowToScratchpad(wm)
+	case "show":
+		return wm.toggleScratchpad()
+	}
+	return fmt.Errorf("unknown scratchpad subcommand %q", args[0])
+}
+
+func cmdWorkspace(wm *WM, args []string) error {
+	if len(args) == 1 {
+		switch args[0] {
+		case "back_and_forth":
+			return wm.switchWorkspaceBackAndForth()
+		case "next":
+			return wm.switchWorkspaceRelative(1, false)
+		case "prev":
+			return wm.switchWorkspaceRelative(-1, false)
+		case "next_nonempty":
+			return wm.switchWorkspaceRelative(1, true)
+		case "prev_nonempty":
+			return wm.switchWorkspaceRelative(-1, true)
+		}
+	}
+	if len(args) < 2 || len(args) > 3 || (len(args) == 3 && args[0] != "rename") {
+		return fmt.Errorf("usage: workspace switch|move <name> | workspace output left|right|<output> | workspace rename [<old>] <new> | " +
+			"workspace scroll on|off|toggle | workspace back_and_forth|next|prev|next_nonempty|prev_nonempty")
+	}
+	switch args[0] {
+	case "switch":
+		return wm.switchWorkspace(args[1])
+	case "move":
+		return handleMoveWindowToWorkspace(wm, args[1])
+	case "output":
+		return handleMoveWorkspaceToOutput(wm, args[1])
+	case "scroll":
+		return handleScrollWorkspace(wm, args[1])
+	case "rename":
+		ws, name := wm.currentOutput().activeWs, args[1]
+		if len(args) == 3 {
+			if ws = wm.lookupWorkspace(args[1]); ws == nil {
+				return fmt.Errorf("no workspace named %q", args[1])
+			}
+			name = args[2]
+		}
+		return wm.renameWorkspace(ws, name)
+	}
+	return fmt.Errorf("unknown workspace subcommand %q", args[0])
+}
+
+func cmdHidden(wm *WM, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("usage: hidden hide|restore [<window>]|pick")
+	}
+	switch args[0] {
+	case "hide":
+		return handleHideWindow(wm)
+	case "restore":
+		var win uint64
+		if len(args) == 2 {
+			var err error
+			if win, err = strconv.ParseUint(args[1], 0, 32); err != nil {
+				return fmt.Errorf("invalid window %q", args[1])
+			}
+		}
+		return wm.restoreHidden(xproto.Window(win))
+	case "pick":
+		return wm.pickHidden()
+	}
+	return fmt.Errorf("unknown hidden subcommand %q", args[0])
+}
+
+func cmdUrgent(wm *WM, args []string) error {
+	if len(args) != 1 || args[0] != "focus" {
+		return fmt.Errorf("usage: urgent focus")
+	}
+	return wm.focusUrgent()
+}
+
+func cmdColumn(wm *WM, args []string) error {
+	if len(args) != 2 || args[0] != "move" || (args[1] != "left" && args[1] != "right") {
+		return fmt.Errorf("usage: column move left|right")
+	}
+	dir, _ := parseDirection(args[1])
+	return handleMoveColumn(wm, dir)
+}
+
+func cmdWindow(wm *WM, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: window swap left|right|up|down|<window> | window insert left|right | " +
+			"window split horizontal|vertical")
+	}
+	switch args[0] {
+	case "split":
+		if args[1] != "horizontal" && args[1] != "vertical" {
+			return fmt.Errorf("usage: window split horizontal|vertical")
+		}
+		return handleSplitWindow(wm, args[1] == "horizontal")
+	case "swap":
+		return handleSwapWindow(wm, args[1])
+	case "insert":
+		if args[1] != "left" && args[1] != "right" {
+			return fmt.Errorf("usage: window insert left|right")
+		}
+		dir, _ := parseDirection(args[1])
+		return handleMoveWindowToNewColumn(wm, dir)
+	}
+	return fmt.Errorf("unknown window subcommand %q", args[0])
+}
+
+func cmdFocus(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: focus left|right|up|down")
+	}
+	dir, ok := parseDirection(args[0])
+	if !ok {
+		return fmt.Errorf("usage: focus left|right|up|down")
+	}
+	return handleFocusDirection(wm, dir)
+}
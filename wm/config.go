@@ -4,6 +4,18 @@ import (
 	"github.com/BurntSushi/xgb/xproto"
 )
 
+// FocusModel decides how the pointer moves the focus between windows
+type FocusModel uint8
+
+const (
+	// FocusSloppy focuses the window entered by the pointer and keeps it focused when the pointer leaves it
+	FocusSloppy FocusModel = iota
+	// FocusFollowsMouse focuses the window entered by the pointer and removes the focus when it leaves to the desktop
+	FocusFollowsMouse
+	// FocusClick focuses the window clicked on, passing the click on to it
+	FocusClick
+)
+
 type Config struct {
 	InnerGap uint16 // Gap around each window, in pixels
 	OuterGap uint16 // Additional gap around the entire workspace, in pixels
@@ -15,14 +27,33 @@ type Config struct {
 	// Shell command to execute after using the "Terminal" binding (Win + Shift + Enter by default)
 	TerminalCommand string
 
+	FocusModel FocusModel
+
+	// Width of the new columns of scrolling workspaces, as a share of the workspace width (0.5 if not set)
+	ScrollColumnWidth float64
+	// Keep the pointer in place when the focus is moved with the keyboard, instead of warping it to the focused window
+	DisablePointerWarp bool
+
 	BorderWidth uint8
 	BorderColor uint32
 
 	TitleBarHeight            uint8
 	TitleBarBgColor           uint32
+	TitleBarBgColorUrgent     uint32 // Background of the title bars of windows needing attention
 	TitleBarFontColorActive   uint32
 	TitleBarFontColorInactive uint32
 	TitleBarFontSize          float64
 
 	Keybindings map[xproto.Keysym]string
+
+	// Shell command listing the hidden windows given on its input, one per line, and printing the chosen one
+	PickerCommand string
+
+	// Rules deciding the placement of new windows, applied in order
+	Rules []Rule
+
+	// Outputs (by their RandR names) on which the workspaces are placed, e.g. {"1": "DP-1", "6": "eDP-1"}.
+	// A number also matches the workspaces with names starting with it, such as "1:web". The workspaces of
+	// disconnected outputs are moved to the primary output until the output is connected again
+	WorkspaceOutputs map[string]string
 }
@@ -0,0 +1,116 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+// addDock adds the frame as a dock of the output it is placed on, and returns that output
+func (wm *WM) addDock(f *frame) (*output, error) {
+	screen := wm.screenGeom()
+	struts, err := wm.xc.GetWindowStruts(f.cli.Window(), screen.W, screen.H)
+	if err != nil {
+		// the dock doesn't reserve any space
+		struts = &x11.Struts{}
+	}
+	area, strut := dockStrut(struts, screen)
+	f.strut = strut
+	o := wm.dockOutput(f)
+	return o, o.addDock(f, area)
+}
+
+// updateDock places the dock again after it changed its struts
+func (wm *WM) updateDock(f *frame) error {
+	for _, o := range wm.outputs {
+		o.deleteFrame(f)
+	}
+	if _, err := wm.addDock(f); err != nil {
+		return err
+	}
+	for _, o := range wm.outputs {
+		if err := wm.renderOutput(o); err != nil {
+			return err
+		}
+	}
+	return wm.updateDesktopHints()
+}
+
+// dockOutput returns the output containing the centre of the dock window. The struts are measured from
+// the edges of the screen, so a dock outside of all the outputs goes to the one overlapping the most
+// with the area it reserves
+func (wm *WM) dockOutput(f *frame) *output {
+	if g, err := xproto.GetGeometry(wm.xc.X(), xproto.Drawable(f.cli.Window())).Reply(); err == nil {
+		x, y := g.X+int16(g.Width/2), g.Y+int16(g.Height/2)
+		if o := wm.findOutput(func(o *output) bool { return o.contains(x, y) }); o != nil {
+			return o
+		}
+	}
+	best, bestSize := wm.outputs[0], 0
+	for _, o := range wm.outputs {
+		geom := o.dockGeom(f)
+		if size := int(geom.W) * int(geom.H); size > bestSize {
+			best, bestSize = o, size
+		}
+	}
+	return best
+}
+
+// dockStrut returns the edge of the screen at which the struts reserve the most space, together with
+// the rectangle reserved there
+func dockStrut(s *x11.Struts, screen client.Geom) (dockArea, client.Geom) {
+	span := func(start, end uint32) uint16 {
+		if end < start {
+			return 0
+		}
+		return uint16(end - start + 1)
+	}
+	switch {
+	case s.Top > 0 && s.Top >= s.Bottom && s.Top >= s.Left && s.Top >= s.Right:
+		return dockAreaTop, client.Geom{
+			X: int16(s.TopStartX), Y: screen.Y,
+			W: span(s.TopStartX, s.TopEndX), H: uint16(s.Top),
+		}
+	case s.Bottom > 0 && s.Bottom >= s.Left && s.Bottom >= s.Right:
+		return dockAreaBottom, client.Geom{
+			X: int16(s.BottomStartX), Y: screen.Y + int16(screen.H) - int16(s.Bottom),
+			W: span(s.BottomStartX, s.BottomEndX), H: uint16(s.Bottom),
+		}
+	case s.Left > 0 && s.Left >= s.Right:
+		return dockAreaLeft, client.Geom{
+			X: screen.X, Y: int16(s.LeftStartY),
+			W: uint16(s.Left), H: span(s.LeftStartY, s.LeftEndY),
+		}
+	case s.Right > 0:
+		return dockAreaRight, client.Geom{
+			X: screen.X + int16(screen.W) - int16(s.Right), Y: int16(s.RightStartY),
+			W: uint16(s.Right), H: span(s.RightStartY, s.RightEndY),
+		}
+	}
+	return dockAreaTop, client.Geom{}
+}
+
+// intersect returns the common part of the two rectangles, which is empty if they don't overlap
+func intersect(a, b client.Geom) client.Geom {
+	x1, y1 := maxInt(int(a.X), int(b.X)), maxInt(int(a.Y), int(b.Y))
+	x2 := minInt(int(a.X)+int(a.W), int(b.X)+int(b.W))
+	y2 := minInt(int(a.Y)+int(a.H), int(b.Y)+int(b.H))
+	if x2 <= x1 || y2 <= y1 {
+		return client.Geom{}
+	}
+	return client.Geom{X: int16(x1), Y: int16(y1), W: uint16(x2 - x1), H: uint16(y2 - y1)}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -3,7 +3,9 @@ package wm
 import (
 	"log"
 
+	"github.com/BurntSushi/xgb/randr"
 	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
 )
 
 type eventHandler struct {
@@ -22,6 +24,8 @@ func (h eventHandler) eventLoop() {
 			h.keyPress(e)
 		case xproto.EnterNotifyEvent:
 			h.enterNotify(e)
+		case xproto.ButtonPressEvent:
+			h.buttonPress(e)
 		case xproto.ConfigureRequestEvent:
 			h.configureRequest(e)
 		case xproto.MapNotifyEvent:
@@ -38,17 +42,33 @@ func (h eventHandler) eventLoop() {
 			h.clientMessage(e)
 		case xproto.ExposeEvent:
 			h.expose(e)
+		case randr.ScreenChangeNotifyEvent:
+			h.screenChangeNotify(e)
 		}
 	}
 }
 
 func (h eventHandler) keyPress(e xproto.KeyPressEvent) {
+	h.wm.noteUserTime(e.Time)
 	if err := h.wm.handleKeyPressEvent(e); err != nil {
 		log.Println(err)
 	}
 }
 
 func (h eventHandler) enterNotify(e xproto.EnterNotifyEvent) {
+	h.wm.pointerMoved(e.RootX, e.RootY)
+	if h.wm.config.FocusModel == FocusClick {
+		return
+	}
+	if e.Event == h.wm.xc.GetRootWindow() {
+		// the pointer left the windows for the desktop
+		if h.wm.config.FocusModel == FocusFollowsMouse && e.Detail == xproto.NotifyDetailInferior {
+			if err := h.wm.removeFocus(); err != nil {
+				log.Println("Failed to remove focus:", err)
+			}
+		}
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Event })
 	if f != nil {
 		if err := h.wm.setFocus(e.Event, e.Time); err != nil {
@@ -57,6 +77,13 @@ func (h eventHandler) enterNotify(e xproto.EnterNotifyEvent) {
 	}
 }
 
+func (h eventHandler) buttonPress(e xproto.ButtonPressEvent) {
+	h.wm.pointerMoved(e.RootX, e.RootY)
+	if err := h.wm.handleButtonPress(e); err != nil {
+		log.Println("Failed to focus the clicked window:", err)
+	}
+}
+
 func (h eventHandler) configureRequest(e xproto.ConfigureRequestEvent) {
 	if err := h.wm.handleConfigureRequest(e); err != nil {
 		log.Println("Failed to configure window:", err)
@@ -74,12 +101,19 @@ func (h eventHandler) mapNotify(e xproto.MapNotifyEvent) {
 
 func (h eventHandler) mapRequest(e xproto.MapRequestEvent) {
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+	if f != nil && f.hidden {
+		// the client restores its minimised window by mapping it again
+		if err := h.wm.unhideFrame(f); err != nil {
+			log.Println("Failed to restore a hidden window:", err)
+		}
+		return
+	}
 	if f != nil {
 		log.Printf("Skipping MapRequest of an already mapped window %d\n", e.Window)
 		return
 	}
 	if attr, err := xproto.GetWindowAttributes(h.wm.xc.X(), e.Window).Reply(); err != nil || !attr.OverrideRedirect {
-		if err := h.wm.manageWindow(e.Window); err != nil {
+		if err := h.wm.manageWindow(e.Window, "", false); err != nil {
 			log.Println("Failed to manage a window:", err)
 		}
 	}
@@ -90,10 +124,19 @@ func (h eventHandler) mapRequest(e xproto.MapRequestEvent) {
 
 func (h eventHandler) unmapNotify(e xproto.UnmapNotifyEvent) {
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
-	if f != nil {
-		if err := f.cli.OnUnmap(); err != nil {
-			log.Println("Failed to unmap frame's parent:", err)
-			return
+	if f == nil {
+		return
+	}
+	withdrawn, err := f.cli.OnUnmap()
+	if err != nil {
+		log.Println("Failed to withdraw the client:", err)
+	}
+	if withdrawn {
+		if err := h.wm.deleteFrame(f); err != nil {
+			log.Println("Failed to delete the frame:", err)
+		}
+		if err := h.wm.updateDesktopHints(); err != nil {
+			log.Printf("Failed to update desktop hints: %v", err)
 		}
 	}
 }
@@ -115,23 +158,113 @@ func (h eventHandler) destroyNotify(e xproto.DestroyNotifyEvent) {
 }
 
 func (h eventHandler) propertyNotify(e xproto.PropertyNotifyEvent) {
-	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+	if e.Window == h.wm.xc.GetRootWindow() {
+		if e.Atom == h.wm.xc.Atom(commandProp) && e.State == xproto.PropertyNewValue {
+			h.wm.handleCommandProp()
+		}
+		return
+	}
+	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window || frm.userTimeWin == e.Window })
 	if f != nil {
+		if e.Atom == h.wm.xc.Atom("_NET_WM_USER_TIME") {
+			// the focused client updates the property on every input it receives
+			if f.cli.Window() == h.wm.activeWin {
+				if t, err := h.wm.xc.GetUserTime(f.userTimeWin); err == nil {
+					h.wm.noteUserTime(t)
+				}
+			}
+			return
+		}
+		if f.cli.Type() == client.TypeDock &&
+			(e.Atom == h.wm.xc.Atom("_NET_WM_STRUT") || e.Atom == h.wm.xc.Atom("_NET_WM_STRUT_PARTIAL")) {
+			if err := h.wm.updateDock(f); err != nil {
+				log.Println("Failed to update dock:", err)
+			}
+			return
+		}
 		f.cli.OnProperty(e.Atom)
+		if e.Atom == h.wm.xc.Atom("WM_HINTS") {
+			if err := h.wm.updateUrgency(f); err != nil {
+				log.Println("Failed to update urgency:", err)
+			}
+		}
+		if ws := f.workspace(); ws != nil && e.Atom == h.wm.xc.Atom("WM_NORMAL_HINTS") {
+			if err := h.wm.renderWorkspace(ws); err != nil {
+				log.Println("Failed to render workspace:", err)
+			}
+		}
 	}
 }
 
 func (h eventHandler) clientMessage(e xproto.ClientMessageEvent) {
 	switch e.Type {
 	case h.wm.xc.Atom("_NET_CURRENT_DESKTOP"):
-		out := h.wm.outputs[0]
+		desktops := h.wm.desktops()
 		index := int(e.Data.Data32[0])
-		if index < len(out.workspaces) {
-			ws := out.workspaces[index]
-			if err := h.wm.switchWorkspace(ws.id); err != nil {
+		if index < len(desktops) {
+			ws := desktops[index]
+			if err := h.wm.switchWorkspace(ws.name); err != nil {
 				log.Printf("Failed to switch workspace: %v", err)
 			}
 		}
+	case h.wm.xc.Atom("_NET_SHOWING_DESKTOP"):
+		if err := h.wm.setShowingDesktop(e.Data.Data32[0] != 0); err != nil {
+			log.Println("Failed to show the desktop:", err)
+		}
+	case h.wm.xc.Atom("_NET_ACTIVE_WINDOW"):
+		f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+		if f == nil || f.cli.Type() == client.TypeDock {
+			return
+		}
+		t := xproto.Timestamp(e.Data.Data32[1])
+		if t == 0 {
+			t = xproto.TimeCurrentTime
+		}
+		if err := h.wm.activateFrame(f, t); err != nil {
+			log.Println("Failed to activate window:", err)
+		}
+	case h.wm.xc.Atom("_NET_REQUEST_FRAME_EXTENTS"):
+		if err := h.wm.requestFrameExtents(e.Window); err != nil {
+			log.Println("Failed to set frame extents:", err)
+		}
+	case h.wm.xc.Atom("WM_CHANGE_STATE"):
+		f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+		if f == nil || e.Data.Data32[0] != client.IconicState {
+			return
+		}
+		if err := h.wm.hideFrame(f); err != nil {
+			log.Println("Failed to hide window:", err)
+		}
+	case h.wm.xc.Atom("_NET_WM_STATE"):
+		f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+		if f == nil || f.cli.Type() == client.TypeDock {
+			return
+		}
+		for _, state := range e.Data.Data32[1:3] {
+			if state == 0 {
+				continue
+			}
+			if err := h.wm.setWindowState(f, e.Data.Data32[0], xproto.Atom(state)); err != nil {
+				log.Println("Failed to change window state:", err)
+			}
+		}
+	case h.wm.xc.Atom("_NET_CLOSE_WINDOW"):
+		if h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window }) == nil {
+			return
+		}
+		if err := h.wm.xc.GracefullyDestroyWindow(e.Window); err != nil {
+			log.Println("Failed to close window:", err)
+		}
+	case h.wm.xc.Atom("_NET_WM_DESKTOP"):
+		f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+		desktops := h.wm.desktops()
+		index := int(e.Data.Data32[0])
+		if f == nil || f.workspace() == nil || index >= len(desktops) {
+			return
+		}
+		if err := h.wm.moveFrameToWorkspace(f, desktops[index].name); err != nil {
+			log.Println("Failed to move window to workspace:", err)
+		}
 	}
 }
 
@@ -145,3 +278,9 @@ func (h eventHandler) expose(e xproto.ExposeEvent) {
 		}
 	}
 }
+
+func (h eventHandler) screenChangeNotify(e randr.ScreenChangeNotifyEvent) {
+	if err := h.wm.updateOutputs(); err != nil {
+		log.Println("Failed to update outputs:", err)
+	}
+}
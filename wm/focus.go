@@ -1,16 +1,58 @@
 package wm
 
 import (
+	"log"
+	"strconv"
+	"strings"
+
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
 )
 
 func (wm *WM) setFocus(win xproto.Window, time xproto.Timestamp) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == win && f.cli.Type() == client.TypeNormal })
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == win && f.cli.Type() != client.TypeDock })
 	if frm == nil && win != wm.xc.GetRootWindow() {
 		return nil
 	}
+	prev := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
 	wm.activeWin = win
+	if prev != nil && prev != frm {
+		prev.cli.SetFocused(false)
+		if wm.config.FocusModel == FocusClick {
+			if err := wm.grabFocusClick(prev.cli.Window()); err != nil {
+				log.Println("Failed to grab the buttons:", err)
+			}
+		}
+		// a client that didn't reset its urgency hint while focused needs attention again
+		if err := wm.updateUrgency(prev); err != nil {
+			return err
+		}
+	}
+	if frm != nil {
+		frm.cli.SetFocused(true)
+		if ws := frm.workspace(); ws != nil && ws.output != nil {
+			wm.lastOutput = ws.output
+		}
+		if wm.config.FocusModel == FocusClick {
+			if err := wm.ungrabFocusClick(win); err != nil {
+				log.Println("Failed to ungrab the buttons:", err)
+			}
+		}
+		if err := wm.clearUrgency(frm); err != nil {
+			return err
+		}
+	}
+	if frm != nil && frm.floating {
+		if err := wm.raiseFrame(frm); err != nil {
+			return err
+		}
+	}
+	// scrolling workspaces bring the focused column into view
+	if frm != nil && frm.tiled() && frm.workspace().scrolling && frm.workspace().scrollTo(frm.topColumn()) {
+		if err := wm.renderWorkspace(frm.workspace()); err != nil {
+			return err
+		}
+	}
 	cookie := xproto.GetProperty(wm.xc.X(), false, win, wm.xc.Atom("WM_PROTOCOLS"), xproto.GetPropertyTypeAny, 0, 64)
 	prop, err := cookie.Reply()
 	if err == nil && wm.takeFocusProp(prop, win, time) {
@@ -23,6 +65,136 @@ func (wm *WM) setFocus(win xproto.Window, time xproto.Timestamp) error {
 	return wm.xc.SetActiveWindow(win)
 }
 
+// activateFrame focuses the frame, switching to its workspace or taking it out of the scratchpad if needed
+func (wm *WM) activateFrame(f *frame, time xproto.Timestamp) error {
+	if err := wm.unhideFrame(f); err != nil {
+		return err
+	}
+	ws := f.workspace()
+	if ws == nil {
+		if !f.scratchpad {
+			return nil
+		}
+		return wm.showScratchpadFrame(f, wm.currentOutput().activeWs)
+	}
+	if ws.output != nil && ws.output.activeWs != ws {
+		if err := wm.switchWorkspace(ws.name); err != nil {
+			return err
+		}
+	}
+	if err := wm.setShowingDesktop(false); err != nil {
+		return err
+	}
+	if err := wm.setFocus(f.cli.Window(), time); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(f)
+}
+
+// focusNewFrame focuses a newly managed frame, unless it would steal the focus from what the user is doing.
+// Such frames, and the frames placed on workspaces that aren't shown, demand attention instead. Frames asking
+// not to be focused when mapped are left alone
+func (wm *WM) focusNewFrame(f *frame) error {
+	ws := f.workspace()
+	if ws == nil || f.hidden || f.cli.Type() == client.TypeNotification {
+		return nil
+	}
+	t, known := wm.newWindowTime(f)
+	if known && t == 0 {
+		return nil
+	}
+	if ws.output != nil && ws.output.activeWs == ws && focusAllowed(t, known, wm.lastUserTime) {
+		return wm.activateFrame(f, xproto.TimeCurrentTime)
+	}
+	f.cli.SetDemandsAttention(true)
+	return wm.updateUrgency(f)
+}
+
+// newWindowTime returns the time of the user action that opened the window, taken from its _NET_WM_USER_TIME
+// or the startup notification ID
+func (wm *WM) newWindowTime(f *frame) (xproto.Timestamp, bool) {
+	if t, err := wm.xc.GetUserTime(f.userTimeWin); err == nil {
+		return t, true
+	}
+	if id, err := wm.xc.GetStartupID(f.cli.Window()); err == nil {
+		return startupTime(id)
+	}
+	return 0, false
+}
+
+// noteUserTime records the time of the user input, unless a later input is already known
+func (wm *WM) noteUserTime(t xproto.Timestamp) {
+	if t != xproto.TimeCurrentTime && (wm.lastUserTime == 0 || !timeBefore(t, wm.lastUserTime)) {
+		wm.lastUserTime = t
+	}
+}
+
+// focusAllowed decides whether a new window may take the focus, given the time of the user action that opened it.
+// Windows opened by an action older than the last user input would take the keyboard away from the user
+func focusAllowed(t xproto.Timestamp, known bool, last xproto.Timestamp) bool {
+	if !known {
+		return true
+	}
+	// the time 0 means that the window doesn't want to be focused when mapped
+	if t == 0 {
+		return false
+	}
+	return last == 0 || !timeBefore(t, last)
+}
+
+// timeBefore compares the X server times, taking into account that they wrap around
+func timeBefore(a, b xproto.Timestamp) bool {
+	return int32(a-b) < 0
+}
+
+// startupTime extracts the time from a startup notification ID of the form "<unique>_TIME<time>"
+func startupTime(id string) (xproto.Timestamp, bool) {
+	i := strings.LastIndex(id, "_TIME")
+	if i < 0 {
+		return 0, false
+	}
+	t, err := strconv.ParseUint(strings.TrimRight(id[i+len("_TIME"):], "\x00"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return xproto.Timestamp(t), true
+}
+
+// grabFocusClick intercepts the clicks on the client window, so that they focus it before reaching the client.
+// The buttons are grabbed only while the window is not focused, so that the clicks in the focused window
+// don't stop the pointer until they are replayed
+func (wm *WM) grabFocusClick(win xproto.Window) error {
+	return xproto.GrabButtonChecked(wm.xc.X(), false, win, uint16(xproto.EventMaskButtonPress),
+		xproto.GrabModeSync, xproto.GrabModeAsync, xproto.WindowNone, xproto.CursorNone,
+		xproto.ButtonIndexAny, xproto.ModMaskAny).Check()
+}
+
+// ungrabFocusClick lets the clicks go straight to the focused client window
+func (wm *WM) ungrabFocusClick(win xproto.Window) error {
+	return xproto.UngrabButtonChecked(wm.xc.X(), xproto.ButtonIndexAny, win, xproto.ModMaskAny).Check()
+}
+
+// handleButtonPress focuses the frame clicked on, either on its title bar or on the client window grabbed
+// for the click to focus. The grabbed clicks are replayed to the client
+func (wm *WM) handleButtonPress(e xproto.ButtonPressEvent) error {
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Event || frm.cli.Parent() == e.Event })
+	if f == nil {
+		return nil
+	}
+	if f.cli.Window() == e.Event {
+		defer func() {
+			if err := xproto.AllowEventsChecked(wm.xc.X(), xproto.AllowReplayPointer, e.Time).Check(); err != nil {
+				log.Println("Failed to replay the click:", err)
+			}
+		}()
+	}
+	wm.noteUserTime(e.Time)
+	if f.cli.Window() == wm.activeWin {
+		return nil
+	}
+	return wm.setFocus(f.cli.Window(), e.Time)
+}
+
 func (wm *WM) removeFocus() error {
 	return wm.setFocus(wm.xc.GetRootWindow(), xproto.TimeCurrentTime)
 }
@@ -56,6 +228,9 @@ func (wm *WM) takeFocusProp(prop *xproto.GetPropertyReply, win xproto.Window, ti
 }
 
 func (wm *WM) warpPointerToFrame(f *frame) error {
+	if wm.config.DisablePointerWarp {
+		return nil
+	}
 	geom := f.cli.Geom()
 	return wm.xc.WarpPointer(geom.X+int16(geom.W/2), geom.Y+int16(geom.H/2))
 }
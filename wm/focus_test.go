@@ -0,0 +1,57 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestFocusAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		time  xproto.Timestamp
+		known bool
+		last  xproto.Timestamp
+		want  bool
+	}{
+		{"unknown time", 0, false, 1000, true},
+		{"no user input yet", 500, true, 0, true},
+		{"opened after the last input", 1500, true, 1000, true},
+		{"opened before the last input", 500, true, 1000, false},
+		{"no focus on map", 0, true, 0, false},
+		{"server time wrapped around", 10, true, 0xfffffff0, true},
+	}
+	for _, tt := range tests {
+		if got := focusAllowed(tt.time, tt.known, tt.last); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStartupTime(t *testing.T) {
+	tests := []struct {
+		id    string
+		time  xproto.Timestamp
+		valid bool
+	}{
+		{"rofi-1234-host-alacritty-0_TIME5678", 5678, true},
+		{"kde-launch_TIME42\x00", 42, true},
+		{"no-time-here", 0, false},
+		{"broken_TIMEabc", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := startupTime(tt.id)
+		if got != tt.time || ok != tt.valid {
+			t.Errorf("startupTime(%q) = %d, %v; want %d, %v", tt.id, got, ok, tt.time, tt.valid)
+		}
+	}
+}
+
+func TestUrgentCounts(t *testing.T) {
+	a, b, c := newWorkspace("1", workspaceConfig{}), newWorkspace("2", workspaceConfig{}), newWorkspace("3", workspaceConfig{})
+	wm := &WM{urgent: []*frame{{ws: c}, {ws: a}, {ws: c}, {}}}
+	counts := wm.urgentCounts([]*workspace{a, b, c})
+	if len(counts) != 3 || counts[0] != 1 || counts[1] != 0 || counts[2] != 2 {
+		t.Errorf("got the urgent counts %v, want [1 0 2]", counts)
+	}
+}
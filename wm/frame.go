@@ -9,7 +9,28 @@ import (
 type frame struct {
 	col    *column
 	cli    *client.Client
-	height uint16
+	weight float64 // share of the column height, relative to the other frames in it
+
+	// floating frames don't belong to any column, so they keep a reference to their workspace
+	ws         *workspace
+	floating   bool
+	floatGeom  client.Geom
+	fullscreen bool
+	above      bool // whether the frame is kept above other frames, requested with _NET_WM_STATE_ABOVE
+	hidden     bool // whether the frame is minimised, kept in the hidden list of its workspace
+	scratchpad bool // whether the frame belongs to the scratchpad, even when shown on a workspace
+
+	// extents are the decorations last published in the _NET_FRAME_EXTENTS property
+	extents *x11.Dimensions
+
+	// strut is the area of the screen reserved by a dock
+	strut client.Geom
+
+	// userTimeWin is the window holding the _NET_WM_USER_TIME property of the client, usually the client window
+	userTimeWin xproto.Window
+
+	// transientFor is the frame of the window this one is a dialog of. Transient frames are kept above it
+	transientFor *frame
 }
 
 func (wm *WM) createFrame(win xproto.Window, typ client.Type) (*frame, error) {
@@ -26,16 +47,35 @@ func (f *frame) workspace() *workspace {
 	if f.col != nil {
 		return f.col.ws
 	}
-	return nil
+	return f.ws
+}
+
+// topColumn returns the column of the workspace holding the tiled frame, possibly in a nested container
+func (f *frame) topColumn() *column {
+	c := f.col
+	for c != nil && c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// tiled reports whether the frame takes a place in one of the columns of its workspace
+func (f *frame) tiled() bool {
+	return !f.floating && !f.hidden && f.col != nil
 }
 
 func (wm *WM) getFrameDecorations(f *frame) x11.Dimensions {
-	if f.cli.Parent() == 0 {
+	if f.cli.Parent() == 0 || f.fullscreen {
 		return x11.Dimensions{Top: 0, Left: 0, Right: 0, Bottom: 0}
 	}
+	return wm.decorations(f.cli.HasTitlebar())
+}
+
+// decorations returns the size of the border, and the titlebar if visible, around a client window
+func (wm *WM) decorations(titlebar bool) x11.Dimensions {
 	var bar uint32
 	border := uint32(wm.config.BorderWidth)
-	if wm.config.TitleBarHeight > 0 {
+	if titlebar && wm.config.TitleBarHeight > 0 {
 		bar = uint32(wm.config.TitleBarHeight) + 1
 	}
 	return x11.Dimensions{
@@ -45,3 +85,75 @@ func (wm *WM) getFrameDecorations(f *frame) x11.Dimensions {
 		Left:   border,
 	}
 }
+
+// clientGeom returns the geometry of the client window relative to the given frame geometry, leaving space
+// for the decorations. When the client's size hints don't allow it to fill the frame, it is centred within it
+func (wm *WM) clientGeom(f *frame, geom client.Geom) client.Geom {
+	d := wm.getFrameDecorations(f)
+	w := int(geom.W) - int(d.Left+d.Right)
+	h := int(geom.H) - int(d.Top+d.Bottom)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	cw, ch := uint16(w), uint16(h)
+	if !f.fullscreen && f.cli.Type() != client.TypeDock {
+		cw, ch = f.cli.SizeHints().Constrain(cw, ch)
+	}
+	return client.Geom{
+		X: int16(d.Left) + int16((w-int(cw))/2),
+		Y: int16(d.Top) + int16((h-int(ch))/2),
+		W: cw,
+		H: ch,
+	}
+}
+
+// updateFrameExtents sets the _NET_FRAME_EXTENTS property of the client if its decorations changed
+func (wm *WM) updateFrameExtents(f *frame) error {
+	d := wm.getFrameDecorations(f)
+	if f.extents != nil && *f.extents == d {
+		return nil
+	}
+	if err := wm.xc.SetFrameExtents(f.cli.Window(), d); err != nil {
+		return err
+	}
+	f.extents = &d
+	return nil
+}
+
+// requestFrameExtents answers _NET_REQUEST_FRAME_EXTENTS by setting the _NET_FRAME_EXTENTS property of a window
+// not managed yet to the decorations it is going to get with the default placement
+func (wm *WM) requestFrameExtents(win xproto.Window) error {
+	typ, err := wm.getWindowType(win)
+	if err != nil {
+		return err
+	}
+	var d x11.Dimensions
+	if typ.Decorated() {
+		p := matchRules(wm.rules, wm.getWindowInfo(win, typ))
+		if p.fullscreen != On {
+			d = wm.decorations(p.titlebar != Off)
+		}
+	}
+	return wm.xc.SetFrameExtents(win, d)
+}
+
+// updateWindowState sets the _NET_WM_STATE property of the client to reflect the state of the frame
+func (wm *WM) updateWindowState(f *frame) error {
+	states := make([]string, 0)
+	if f.fullscreen {
+		states = append(states, "_NET_WM_STATE_FULLSCREEN")
+	}
+	if f.above {
+		states = append(states, "_NET_WM_STATE_ABOVE")
+	}
+	if f.hidden {
+		states = append(states, "_NET_WM_STATE_HIDDEN")
+	}
+	if f.cli.DemandsAttention() {
+		states = append(states, "_NET_WM_STATE_DEMANDS_ATTENTION")
+	}
+	return wm.xc.SetWindowState(f.cli.Window(), states)
+}
@@ -0,0 +1,168 @@
+package wm
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// hideFrame minimises the frame, moving it with its dialogs from the layout of its workspace to the hidden list
+func (wm *WM) hideFrame(f *frame) error {
+	ws := f.workspace()
+	if ws == nil || f.hidden {
+		return nil
+	}
+	transients := ws.transients(f)
+	if !ws.deleteFrame(f) {
+		return fmt.Errorf("frame not contained within workspace %s", ws.name)
+	}
+	if err := ws.addHiddenFrame(f); err != nil {
+		return fmt.Errorf("failed to unmap the frame: %v", err)
+	}
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	for _, t := range transients {
+		if err := wm.hideFrame(t); err != nil {
+			return fmt.Errorf("failed to hide transient frame: %v", err)
+		}
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		return fmt.Errorf("failed to update desktop hints: %v", err)
+	}
+	if f.cli.Window() == wm.activeWin {
+		return wm.removeFocus()
+	}
+	return nil
+}
+
+// unhideFrame puts the hidden frame back into the layout of its workspace, along with its dialogs
+func (wm *WM) unhideFrame(f *frame) error {
+	ws := f.workspace()
+	if ws == nil || !f.hidden {
+		return nil
+	}
+	ws.deleteFrame(f)
+	var err error
+	if f.floating {
+		err = ws.addFloatingFrame(f, f.floatGeom)
+	} else {
+		err = ws.addFrame(f)
+	}
+	if err != nil {
+		return err
+	}
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	for _, t := range append([]*frame(nil), ws.hidden...) {
+		if t.transientFor == f {
+			if err := wm.unhideFrame(t); err != nil {
+				return fmt.Errorf("failed to restore transient frame: %v", err)
+			}
+		}
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	return wm.updateDesktopHints()
+}
+
+// restoreHidden restores the hidden frame of the window and focuses it, or the frame hidden last
+// on the current workspace if win is zero
+func (wm *WM) restoreHidden(win xproto.Window) error {
+	var f *frame
+	if win == 0 {
+		ws := wm.currentOutput().activeWs
+		for i := len(ws.hidden) - 1; i >= 0 && f == nil; i-- {
+			if ws.hidden[i].transientFor == nil {
+				f = ws.hidden[i]
+			}
+		}
+	} else {
+		f = wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == win && frm.hidden })
+	}
+	if f == nil {
+		return nil
+	}
+	return wm.activateFrame(f, xproto.TimeCurrentTime)
+}
+
+// pickHidden runs the picker command with the hidden windows of all workspaces on its input. The window
+// chosen by the user is restored once the picker exits
+func (wm *WM) pickHidden() error {
+	var lines []string
+	for _, ws := range wm.workspaces {
+		for _, f := range ws.hidden {
+			title, _ := wm.xc.GetWindowTitle(f.cli.Window())
+			lines = append(lines, fmt.Sprintf("%d\t[%s] %s", f.cli.Window(), ws.name, strings.TrimRight(title, "\x00")))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	cmd := exec.Command(wm.config.Shell, "-c", wm.config.PickerCommand)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	// the picker runs concurrently with the event loop, so the choice is sent back as a command
+	go func() {
+		if err := cmd.Run(); err != nil {
+			log.Printf("Failed to run picker (%s): %v\n", cmd, err)
+			return
+		}
+		id := strings.SplitN(out.String(), "\t", 2)[0]
+		if _, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32); err != nil {
+			return
+		}
+		if err := SendCommand("hidden restore " + strings.TrimSpace(id)); err != nil {
+			log.Println("Failed to restore the picked window:", err)
+		}
+	}()
+	return nil
+}
+
+// setWindowState applies the action of a _NET_WM_STATE client message (0 to remove, 1 to add and 2 to toggle)
+// to the state of the frame
+func (wm *WM) setWindowState(f *frame, action uint32, state xproto.Atom) error {
+	apply := func(current bool) bool {
+		switch action {
+		case 0:
+			return false
+		case 1:
+			return true
+		}
+		return !current
+	}
+	switch state {
+	case wm.xc.Atom("_NET_WM_STATE_HIDDEN"):
+		if apply(f.hidden) {
+			return wm.hideFrame(f)
+		}
+		return wm.unhideFrame(f)
+	case wm.xc.Atom("_NET_WM_STATE_FULLSCREEN"):
+		f.fullscreen = apply(f.fullscreen)
+	case wm.xc.Atom("_NET_WM_STATE_ABOVE"):
+		f.above = apply(f.above)
+	case wm.xc.Atom("_NET_WM_STATE_DEMANDS_ATTENTION"):
+		f.cli.SetDemandsAttention(apply(f.cli.DemandsAttention()))
+		return wm.updateUrgency(f)
+	default:
+		return nil
+	}
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	if ws := f.workspace(); ws != nil {
+		return wm.renderWorkspace(ws)
+	}
+	return nil
+}
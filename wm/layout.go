@@ -0,0 +1,318 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+// layoutProp is the root window property keeping the layout across restarts
+const layoutProp = "_MARWIND_LAYOUT"
+
+// layoutState is the serialised arrangement of the workspaces and their frames
+type layoutState struct {
+	Outputs    []outputState    `json:"outputs"`
+	Workspaces []workspaceState `json:"workspaces"`
+	Scratchpad []frameState     `json:"scratchpad,omitempty"`
+	Focus      xproto.Window    `json:"focus,omitempty"`
+}
+
+type outputState struct {
+	Name   string `json:"name"`
+	Active string `json:"active"`
+}
+
+type workspaceState struct {
+	Name     string        `json:"name"`
+	Output   string        `json:"output"`
+	Columns  []columnState `json:"columns"`
+	Floating []frameState  `json:"floating,omitempty"`
+	Hidden   []frameState  `json:"hidden,omitempty"`
+
+	// Scrolling workspaces keep the widths of the columns as shares of the workspace width
+	Scrolling bool `json:"scrolling,omitempty"`
+	Scroll    int  `json:"scroll,omitempty"`
+}
+
+// columnState keeps the width of the column and the heights of the frames as weights relative to the other
+// columns and frames
+type columnState struct {
+	Width  float64      `json:"width"`
+	Frames []frameState `json:"frames"`
+}
+
+// frameState describes a frame, or a nested container if Split is set. The height is the weight relative
+// to the other items of the container
+type frameState struct {
+	Window     xproto.Window `json:"window"`
+	Height     float64       `json:"height,omitempty"`
+	Split      *splitState   `json:"split,omitempty"`
+	Geom       client.Geom   `json:"geom"`
+	Fullscreen bool          `json:"fullscreen,omitempty"`
+	NoTitlebar bool          `json:"no_titlebar,omitempty"`
+	Scratchpad bool          `json:"scratchpad,omitempty"`
+	Floating   bool          `json:"floating,omitempty"`
+}
+
+// splitState describes the items of a nested container
+type splitState struct {
+	Horizontal bool         `json:"horizontal,omitempty"`
+	Frames     []frameState `json:"frames"`
+}
+
+// normalize scales the widths of the columns and the heights of their frames so that they add up to 1.
+// Layouts saved with the sizes in pixels are read as relative sizes this way. The widths of the columns
+// of scrolling workspaces are left as they are
+func (l *layoutState) normalize() {
+	for _, wss := range l.Workspaces {
+		widths := make([]*float64, len(wss.Columns))
+		for i := range wss.Columns {
+			widths[i] = &wss.Columns[i].Width
+			normalizeStates(wss.Columns[i].Frames)
+		}
+		if !wss.Scrolling {
+			normalize(widths)
+		}
+	}
+}
+
+func normalizeStates(states []frameState) {
+	weights := make([]*float64, len(states))
+	for i := range states {
+		weights[i] = &states[i].Height
+		if states[i].Split != nil {
+			normalizeStates(states[i].Split.Frames)
+		}
+	}
+	normalize(weights)
+}
+
+// saveLayout serialises the arrangement of all the workspaces attached to outputs
+func (wm *WM) saveLayout() *layoutState {
+	l := &layoutState{Focus: wm.activeWin}
+	for _, o := range wm.outputs {
+		l.Outputs = append(l.Outputs, outputState{Name: o.name, Active: o.activeWs.name})
+		for _, ws := range o.workspaces {
+			wss := workspaceState{Name: ws.name, Output: o.name, Scrolling: ws.scrolling, Scroll: ws.scroll}
+			for _, col := range ws.columns {
+				wss.Columns = append(wss.Columns, columnState{Width: col.weight, Frames: saveNodes(col)})
+			}
+			for _, f := range ws.floating {
+				wss.Floating = append(wss.Floating, saveFrame(f))
+			}
+			for _, f := range ws.hidden {
+				wss.Hidden = append(wss.Hidden, saveFrame(f))
+			}
+			l.Workspaces = append(l.Workspaces, wss)
+		}
+	}
+	for _, f := range wm.scratchpad {
+		l.Scratchpad = append(l.Scratchpad, saveFrame(f))
+	}
+	return l
+}
+
+// saveNodes serialises the items of the container, recursively
+func saveNodes(c *column) []frameState {
+	var states []frameState
+	for _, n := range c.nodes {
+		switch n := n.(type) {
+		case *frame:
+			states = append(states, saveFrame(n))
+		case *column:
+			split := &splitState{Horizontal: n.horizontal, Frames: saveNodes(n)}
+			states = append(states, frameState{Height: n.weight, Split: split})
+		}
+	}
+	return states
+}
+
+func saveFrame(f *frame) frameState {
+	return frameState{
+		Window:     f.cli.Window(),
+		Height:     f.weight,
+		Geom:       f.floatGeom,
+		Fullscreen: f.fullscreen,
+		NoTitlebar: !f.cli.HasTitlebar(),
+		Scratchpad: f.scratchpad,
+		Floating:   f.floating,
+	}
+}
+
+// loadLayout reads the layout saved by the previous instance of the WM, if there is any
+func (wm *WM) loadLayout() *layoutState {
+	data, err := wm.xc.GetRootProp(layoutProp)
+	if err != nil {
+		return nil
+	}
+	var l layoutState
+	if err := json.Unmarshal(data, &l); err != nil {
+		log.Println("Failed to parse the saved layout:", err)
+		return nil
+	}
+	return &l
+}
+
+// restart saves the layout in a property of the root window and replaces the running process with
+// a new instance of the executable, which restores the layout when managing the existing windows
+func (wm *WM) restart() error {
+	data, err := json.Marshal(wm.saveLayout())
+	if err != nil {
+		return fmt.Errorf("failed to serialise layout: %v", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the executable: %v", err)
+	}
+	if err := wm.xc.SetRootProp(layoutProp, data); err != nil {
+		return fmt.Errorf("failed to save layout: %v", err)
+	}
+	// The X connection is closed on exec, upon which the server reparents all clients to the root window
+	if err := syscall.Exec(exe, os.Args, os.Environ()); err != nil {
+		_ = wm.xc.DeleteRootProp(layoutProp)
+		return fmt.Errorf("failed to restart: %v", err)
+	}
+	return nil
+}
+
+// restoreLayout rearranges the already managed frames to match the saved layout. Frames of the windows
+// missing from the layout are left where they were initially placed
+func (wm *WM) restoreLayout(l *layoutState) error {
+	for _, wss := range l.Workspaces {
+		ws, err := wm.ensureWorkspace(wss.Name)
+		if err != nil {
+			return err
+		}
+		var columns []*column
+		for _, cs := range wss.Columns {
+			col := &column{ws: ws, weight: cs.Width}
+			wm.restoreNodes(col, cs.Frames)
+			if len(col.nodes) > 0 {
+				columns = append(columns, col)
+			}
+		}
+		ws.columns = append(columns, ws.columns...)
+		ws.scrolling = wss.Scrolling
+		ws.scroll = wss.Scroll
+		ws.fitColumns()
+		for _, fs := range wss.Floating {
+			if f := wm.detachFrame(fs.Window); f != nil {
+				wm.restoreFrame(f, fs)
+				f.ws = ws
+				f.floating = true
+				f.floatGeom = fs.Geom
+				ws.floating = append(ws.floating, f)
+			}
+		}
+		for _, fs := range wss.Hidden {
+			if f := wm.detachFrame(fs.Window); f != nil {
+				f.floating = fs.Floating
+				f.floatGeom = fs.Geom
+				f.weight = fs.Height
+				if err := ws.addHiddenFrame(f); err != nil {
+					return err
+				}
+				wm.restoreFrame(f, fs)
+			}
+		}
+	}
+	for _, fs := range l.Scratchpad {
+		if f := wm.detachFrame(fs.Window); f != nil {
+			wm.restoreFrame(f, fs)
+			f.floating = true
+			f.floatGeom = fs.Geom
+			if err := f.cli.Unmap(); err != nil {
+				return err
+			}
+			wm.scratchpad = append(wm.scratchpad, f)
+		}
+	}
+
+	for _, ost := range l.Outputs {
+		o := wm.findOutput(func(o *output) bool { return o.name == ost.Name })
+		if o == nil {
+			continue
+		}
+		if ws := o.findWorkspace(func(ws *workspace) bool { return ws.name == ost.Active }); ws != nil {
+			o.activeWs = ws
+		}
+	}
+	var err error
+	for _, o := range wm.outputs {
+		for _, ws := range append([]*workspace(nil), o.workspaces...) {
+			switch {
+			case ws == o.activeWs:
+				err = ws.show()
+			case len(ws.frames()) == 0:
+				wm.pruneWorkspace(ws)
+			default:
+				err = ws.hide()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := wm.renderOutput(o); err != nil {
+			return err
+		}
+	}
+	if l.Focus != 0 {
+		return wm.setFocus(l.Focus, xproto.TimeCurrentTime)
+	}
+	return nil
+}
+
+// restoreNodes fills the container with the saved items, skipping the windows that no longer exist
+func (wm *WM) restoreNodes(c *column, states []frameState) {
+	for _, fs := range states {
+		var n node
+		if fs.Split != nil {
+			sub := &column{ws: c.ws, horizontal: fs.Split.Horizontal}
+			wm.restoreNodes(sub, fs.Split.Frames)
+			switch len(sub.nodes) {
+			case 0:
+				continue
+			case 1:
+				n = sub.nodes[0]
+			default:
+				n = sub
+			}
+		} else if f := wm.detachFrame(fs.Window); f != nil {
+			wm.restoreFrame(f, fs)
+			n = f
+		} else {
+			continue
+		}
+		*n.share() = fs.Height
+		n.setContainer(c)
+		c.nodes = append(c.nodes, n)
+	}
+	normalize(c.weights())
+}
+
+// detachFrame removes the frame of the given window from its workspace and returns it
+func (wm *WM) detachFrame(win xproto.Window) *frame {
+	f := wm.findFrame(func(f *frame) bool { return f.cli.Window() == win && f.workspace() != nil })
+	if f == nil {
+		return nil
+	}
+	f.workspace().deleteFrame(f)
+	f.col = nil
+	f.ws = nil
+	f.floating = false
+	return f
+}
+
+func (wm *WM) restoreFrame(f *frame, fs frameState) {
+	f.fullscreen = fs.Fullscreen
+	f.scratchpad = fs.Scratchpad
+	f.cli.SetTitlebar(!fs.NoTitlebar)
+	if err := wm.updateWindowState(f); err != nil {
+		log.Printf("Failed to update the state of window %d: %v\n", fs.Window, err)
+	}
+}
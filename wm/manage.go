@@ -2,18 +2,24 @@ package wm
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/BurntSushi/xgb/xproto"
 
 	"github.com/patrislav/marwind/client"
 )
 
-func (wm *WM) manageWindow(win xproto.Window) error {
+// manageWindow frames the window and places it according to the rules. If wsName is not empty, it overrides
+// the workspace chosen by the rules. New windows are focused, unless they were adopted from a previous WM
+func (wm *WM) manageWindow(win xproto.Window, wsName string, adopted bool) error {
 	typ, err := wm.getWindowType(win)
 	if err != nil {
 		return fmt.Errorf("failed to get window type: %v", err)
 	}
-	mask := uint32(xproto.EventMaskStructureNotify | xproto.EventMaskEnterWindow | xproto.EventMaskPropertyChange)
+	mask := uint32(xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange)
+	if wm.config.FocusModel != FocusClick {
+		mask |= xproto.EventMaskEnterWindow
+	}
 	cookie := xproto.ChangeWindowAttributesChecked(wm.xc.X(), win, xproto.CwEventMask, []uint32{mask})
 	if err := cookie.Check(); err != nil {
 		return fmt.Errorf("failed to change window attributes: %v", err)
@@ -22,43 +28,199 @@ func (wm *WM) manageWindow(win xproto.Window) error {
 	if err != nil {
 		return fmt.Errorf("failed to frame the window: %v", err)
 	}
+	wm.stack = append(wm.stack, f)
 	switch f.cli.Type() {
-	case client.TypeNormal:
-		ws := wm.outputs[0].activeWs
-		if err := ws.addFrame(f); err != nil {
+	case client.TypeDock:
+		o, err := wm.addDock(f)
+		if err != nil {
+			return fmt.Errorf("failed to add dock: %v", err)
+		}
+		if err := wm.renderOutput(o); err != nil {
+			return fmt.Errorf("failed to render output: %v", err)
+		}
+		if err := wm.updateDesktopHints(); err != nil {
+			return fmt.Errorf("failed to update desktop hints: %v", err)
+		}
+	case client.TypeDesktop:
+		// desktop windows stay on the output they were placed on, without reserving any space
+		if err := wm.dockOutput(f).addDesktopWin(f); err != nil {
+			return fmt.Errorf("failed to add desktop window: %v", err)
+		}
+		if err := wm.restack(); err != nil {
+			return fmt.Errorf("failed to restack: %v", err)
+		}
+		if err := wm.updateDesktopHints(); err != nil {
+			return fmt.Errorf("failed to update desktop hints: %v", err)
+		}
+	default:
+		if err := wm.setShowingDesktop(false); err != nil {
+			return err
+		}
+		f.above = wm.xc.HasWindowState(win, "_NET_WM_STATE_ABOVE")
+		f.cli.SetDemandsAttention(wm.xc.HasWindowState(win, "_NET_WM_STATE_DEMANDS_ATTENTION"))
+		if wm.config.FocusModel == FocusClick {
+			if err := wm.grabFocusClick(win); err != nil {
+				return fmt.Errorf("failed to grab the buttons: %v", err)
+			}
+		}
+		f.userTimeWin = win
+		if utw, err := wm.xc.GetUserTimeWindow(win); err == nil && utw != 0 {
+			cookie := xproto.ChangeWindowAttributesChecked(wm.xc.X(), utw, xproto.CwEventMask,
+				[]uint32{xproto.EventMaskPropertyChange})
+			if err := cookie.Check(); err == nil {
+				f.userTimeWin = utw
+			}
+		}
+		if parent, err := wm.xc.GetTransientFor(win); err == nil {
+			f.transientFor = wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == parent })
+		}
+		info := wm.getWindowInfo(win, typ)
+		p := matchRules(wm.rules, info)
+		if wsName != "" {
+			p.workspace = wsName
+		}
+		var ws *workspace
+		// adopted windows go back to where they were, without taking the places reserved for new windows
+		var ph *placeholder
+		if !adopted {
+			ph = wm.takePlaceholder(info)
+		}
+		if ph != nil {
+			ws, err = wm.fillPlaceholder(f, ph)
+		} else {
+			ws, err = wm.placeFrame(f, p)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to add frame: %v", err)
 		}
+		// frames on hidden workspaces aren't rendered, but their extents are published right away
+		if err := wm.updateFrameExtents(f); err != nil {
+			return fmt.Errorf("failed to set frame extents: %v", err)
+		}
 		if err := wm.renderWorkspace(ws); err != nil {
 			return fmt.Errorf("failed to render workspace: %v", err)
 		}
-	case client.TypeDock:
-		if err := wm.outputs[0].addDock(f); err != nil {
-			return fmt.Errorf("failed to add dock: %v", err)
+		if err := wm.updateUrgency(f); err != nil {
+			return fmt.Errorf("failed to update urgency: %v", err)
 		}
-		if err := wm.renderOutput(wm.outputs[0]); err != nil {
-			return fmt.Errorf("failed to render output: %v", err)
+		if !adopted {
+			if err := wm.focusNewFrame(f); err != nil {
+				return fmt.Errorf("failed to focus the new window: %v", err)
+			}
 		}
 	}
 	return nil
 }
 
+// placeFrame adds a newly managed frame to the workspace selected by the placement and returns that workspace
+func (wm *WM) placeFrame(f *frame, p placement) (*workspace, error) {
+	o := wm.currentOutput()
+	if p.output != "" {
+		if out := wm.findOutput(func(o *output) bool { return o.name == p.output }); out != nil {
+			o = out
+		} else {
+			log.Printf("WARNING: placeFrame: no output named %q\n", p.output)
+		}
+	}
+	ws := o.activeWs
+	if f.transientFor != nil && f.transientFor.workspace() != nil {
+		ws = f.transientFor.workspace()
+	}
+	if p.workspace != "" {
+		var err error
+		if ws, err = wm.ensureWorkspace(p.workspace); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.titlebar == Off {
+		f.cli.SetTitlebar(false)
+	}
+	f.fullscreen = p.fullscreen == On
+	if err := wm.updateWindowState(f); err != nil {
+		return nil, err
+	}
+	floatByDefault := f.cli.Type().Floating() || f.transientFor != nil || f.cli.SizeHints().Fixed()
+	if p.floating == On || (p.floating == Unset && floatByDefault) {
+		within := ws.area()
+		if f.transientFor != nil && f.transientFor.workspace() == ws {
+			within = f.transientFor.cli.Geom()
+		}
+		geom := wm.floatingGeom(f, within, p.width, p.height)
+		if f.cli.Type() == client.TypeNotification {
+			// notifications are shown where they placed themselves
+			if g, err := xproto.GetGeometry(wm.xc.X(), xproto.Drawable(f.cli.Window())).Reply(); err == nil {
+				geom.X, geom.Y = g.X, g.Y
+			}
+		}
+		return ws, ws.addFloatingFrame(f, geom)
+	}
+	if p.column > 0 {
+		if err := ws.addFrameToColumn(f, p.column-1); err != nil {
+			return nil, err
+		}
+	} else if err := ws.addFrame(f); err != nil {
+		return nil, err
+	}
+	if p.width > 0 {
+		ws.setColumnWidth(f.topColumn(), p.width)
+	}
+	if p.height > 0 {
+		ws.setFrameHeight(f, p.height)
+	}
+	return ws, nil
+}
+
+// floatingGeom returns the geometry of a floating frame centred within the given area. The zero width and height
+// are replaced with the current size of the client window
+func (wm *WM) floatingGeom(f *frame, within client.Geom, width, height uint16) client.Geom {
+	if width == 0 || height == 0 {
+		if g, err := xproto.GetGeometry(wm.xc.X(), xproto.Drawable(f.cli.Window())).Reply(); err == nil {
+			if width == 0 {
+				width = g.Width
+			}
+			if height == 0 {
+				height = g.Height
+			}
+		}
+	}
+	d := wm.getFrameDecorations(f)
+	width += uint16(d.Left + d.Right)
+	height += uint16(d.Top + d.Bottom)
+	return client.Geom{
+		X: within.X + int16((int(within.W)-int(width))/2),
+		Y: within.Y + int16((int(within.H)-int(height))/2),
+		W: width,
+		H: height,
+	}
+}
+
 func (wm *WM) getWindowType(win xproto.Window) (client.Type, error) {
 	typeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE")
-	dockTypeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE_DOCK")
-	normalTypeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE_NORMAL")
+	types := map[xproto.Atom]client.Type{
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_DOCK"):         client.TypeDock,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_NORMAL"):       client.TypeNormal,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_DIALOG"):       client.TypeDialog,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_SPLASH"):       client.TypeSplash,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_UTILITY"):      client.TypeUtility,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_TOOLBAR"):      client.TypeToolbar,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_DESKTOP"):      client.TypeDesktop,
+		wm.xc.Atom("_NET_WM_WINDOW_TYPE_NOTIFICATION"): client.TypeNotification,
+	}
 	prop, err := xproto.GetProperty(wm.xc.X(), false, win, typeAtom, xproto.GetPropertyTypeAny, 0, 64).Reply()
 	if err != nil {
 		return client.TypeUnknown, err
 	}
 	if prop != nil {
 		for v := prop.Value; len(v) >= 4; v = v[4:] {
-			switch xproto.Atom(uint32(v[0]) | uint32(v[1])<<8 | uint32(v[2])<<16 | uint32(v[3])<<24) {
-			case dockTypeAtom:
-				return client.TypeDock, nil
-			case normalTypeAtom:
-				return client.TypeNormal, nil
+			if typ, ok := types[xproto.Atom(uint32(v[0])|uint32(v[1])<<8|uint32(v[2])<<16|uint32(v[3])<<24)]; ok {
+				return typ, nil
 			}
 		}
 	}
+	// Transient windows without a known type should be treated as dialogs
+	if _, err := wm.xc.GetTransientFor(win); err == nil {
+		return client.TypeDialog, nil
+	}
 	return client.TypeNormal, nil
 }
@@ -2,6 +2,7 @@ package wm
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/BurntSushi/xgb/xproto"
 )
@@ -15,6 +16,21 @@ const (
 	MoveDown
 )
 
+// parseDirection returns the direction of the given name used in the commands
+func parseDirection(name string) (MoveDirection, bool) {
+	switch name {
+	case "left":
+		return MoveLeft, true
+	case "right":
+		return MoveRight, true
+	case "up":
+		return MoveUp, true
+	case "down":
+		return MoveDown, true
+	}
+	return 0, false
+}
+
 type ResizeDirection uint8
 
 const (
@@ -22,14 +38,20 @@ const (
 	ResizeHoriz
 )
 
-func (wm *WM) switchWorkspace(id uint8) error {
-	ws, err := wm.ensureWorkspace(id)
+// switchWorkspace shows the named workspace on its output, creating it if needed
+func (wm *WM) switchWorkspace(name string) error {
+	ws, err := wm.ensureWorkspace(name)
 	if err != nil {
 		return fmt.Errorf("failed to ensure workspace: %v", err)
 	}
+	if err := wm.setShowingDesktop(false); err != nil {
+		return err
+	}
+	prev := ws.output.activeWs
 	if err := ws.output.switchWorkspace(ws); err != nil {
 		return fmt.Errorf("output unable to switch workpace: %v", err)
 	}
+	wm.pruneWorkspace(prev)
 	if err := wm.renderWorkspace(ws); err != nil {
 		return fmt.Errorf("wm.renderWorkspace: %w", err)
 	}
@@ -42,8 +64,8 @@ func (wm *WM) switchWorkspace(id uint8) error {
 
 	// TODO: temporary solution! Focuses always the first window of the first column
 	// Better approach: implement a window focus stack for each workspace, on switch focus the top-of-stack window
-	if len(ws.columns) > 0 && len(ws.columns[0].frames) > 0 {
-		win := ws.columns[0].frames[0].cli.Window()
+	if len(ws.columns) > 0 && len(ws.columns[0].frames()) > 0 {
+		win := ws.columns[0].frames()[0].cli.Window()
 		if err := wm.setFocus(win, xproto.TimeCurrentTime); err != nil {
 			return fmt.Errorf("failed to set focus: %w", err)
 		}
@@ -51,23 +73,82 @@ func (wm *WM) switchWorkspace(id uint8) error {
 	return nil
 }
 
-func (wm *WM) moveFrameToWorkspace(f *frame, wsID uint8) error {
-	current := wm.outputs[0].activeWs
-	next, err := wm.ensureWorkspace(wsID)
+// switchWorkspaceBackAndForth shows the workspace previously shown on the current output
+func (wm *WM) switchWorkspaceBackAndForth() error {
+	o := wm.currentOutput()
+	if o.prevWs == "" {
+		return nil
+	}
+	return wm.switchWorkspace(o.prevWs)
+}
+
+// switchWorkspaceRelative shows the workspace at the offset from the active one on the current output,
+// wrapping around at the ends. If nonEmpty is set, the workspaces without any frames are skipped
+func (wm *WM) switchWorkspaceRelative(offset int, nonEmpty bool) error {
+	o := wm.currentOutput()
+	n := len(o.workspaces)
+	start := 0
+	for i, ws := range o.workspaces {
+		if ws == o.activeWs {
+			start = i
+		}
+	}
+	for i := (start + offset%n + n) % n; i != start; i = (i + offset%n + n) % n {
+		if ws := o.workspaces[i]; !nonEmpty || len(ws.frames()) > 0 {
+			return wm.switchWorkspace(ws.name)
+		}
+	}
+	return nil
+}
+
+// currentOutput returns the output of the focused window or, if nothing is focused, the output that was
+// focused or under the pointer last
+func (wm *WM) currentOutput() *output {
+	f := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	if f != nil && f.workspace() != nil && f.workspace().output != nil {
+		return f.workspace().output
+	}
+	if wm.lastOutput != nil {
+		return wm.lastOutput
+	}
+	return wm.outputs[0]
+}
+
+// pointerMoved notes the output under the pointer at the given position, as the current one
+func (wm *WM) pointerMoved(x, y int16) {
+	if o := wm.findOutput(func(o *output) bool { return o.contains(x, y) }); o != nil {
+		wm.lastOutput = o
+	}
+}
+
+func (wm *WM) moveFrameToWorkspace(f *frame, name string) error {
+	current := f.workspace()
+	next, err := wm.ensureWorkspace(name)
 	if err != nil {
 		return err
 	}
 	if next == current {
 		return nil
 	}
+	hidden := f.hidden
 	if !current.deleteFrame(f) {
-		return fmt.Errorf("frame not contained within workspace %d", wsID)
+		return fmt.Errorf("frame not contained within workspace %s", current.name)
+	}
+	switch {
+	case hidden:
+		err = next.addHiddenFrame(f)
+	case f.floating:
+		err = next.addFloatingFrame(f, f.floatGeom)
+	default:
+		err = next.addFrame(f)
 	}
-	if err := next.addFrame(f); err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to add the frame to the next workspace: %v", err)
 	}
-	if err := f.cli.Unmap(); err != nil {
-		return fmt.Errorf("failed to unmap the frame: %v", err)
+	for _, t := range current.transients(f) {
+		if err := wm.moveFrameToWorkspace(t, next.name); err != nil {
+			return fmt.Errorf("failed to move transient frame: %v", err)
+		}
 	}
 	if err := wm.renderWorkspace(next); err != nil {
 		return fmt.Errorf("failed to render next workspace: %v", err)
@@ -75,31 +156,173 @@ func (wm *WM) moveFrameToWorkspace(f *frame, wsID uint8) error {
 	if err := wm.renderWorkspace(current); err != nil {
 		return fmt.Errorf("failed to render previous workspace: %v", err)
 	}
+	wm.pruneWorkspace(current)
 	if err := wm.updateDesktopHints(); err != nil {
 		return fmt.Errorf("failed to update desktop hints: %v", err)
 	}
 	return nil
 }
 
-// ensureWorkspace looks up a workspace by ID, adding it to the current output if needed
-func (wm *WM) ensureWorkspace(id uint8) (*workspace, error) {
-	var nextWs *workspace
-	for _, ws := range wm.workspaces {
-		if ws.id == id {
-			nextWs = ws
-			break
-		}
+// lookupWorkspace finds the workspace by its name. A name consisting only of a number also matches
+// the workspace with that number, so that "2" finds "2:web"
+func (wm *WM) lookupWorkspace(name string) *workspace {
+	if ws := wm.findWorkspace(func(ws *workspace) bool { return ws.name == name }); ws != nil {
+		return ws
+	}
+	if num, err := strconv.Atoi(name); err == nil && num >= 0 {
+		return wm.findWorkspace(func(ws *workspace) bool { return ws.num == num })
+	}
+	return nil
+}
+
+// ensureWorkspace looks up a workspace by name, creating it and adding it to the current output if needed
+func (wm *WM) ensureWorkspace(name string) (*workspace, error) {
+	if name == "" {
+		return nil, fmt.Errorf("empty workspace name")
 	}
+	nextWs := wm.lookupWorkspace(name)
 	if nextWs == nil {
-		return nil, fmt.Errorf("no workspace with ID %d", id)
+		nextWs = wm.newWorkspace(name)
 	}
-	switch {
-	case nextWs.output == nil:
-		if err := wm.outputs[0].addWorkspace(nextWs); err != nil {
+	if nextWs.output == nil {
+		if err := wm.workspaceOutput(nextWs).addWorkspace(nextWs); err != nil {
 			return nil, err
 		}
-	case nextWs.output != wm.outputs[0]:
-		return nil, fmt.Errorf("multiple outputs not supported yet")
 	}
 	return nextWs, nil
 }
+
+// newWorkspace creates a workspace not yet attached to any output
+func (wm *WM) newWorkspace(name string) *workspace {
+	ws := newWorkspace(name, workspaceConfig{gap: wm.config.OuterGap, scrollWidth: wm.config.ScrollColumnWidth})
+	wm.workspaces = append(wm.workspaces, ws)
+	return ws
+}
+
+// freeWorkspaceName returns the lowest number not used by any workspace
+func (wm *WM) freeWorkspaceName() string {
+	for num := 1; ; num++ {
+		if wm.findWorkspace(func(ws *workspace) bool { return ws.num == num }) == nil {
+			return strconv.Itoa(num)
+		}
+	}
+}
+
+// pruneWorkspace removes the workspace if it is empty and not shown on its output
+func (wm *WM) pruneWorkspace(ws *workspace) {
+	if ws == nil || len(ws.frames()) > 0 || (ws.output != nil && ws.output.activeWs == ws) {
+		return
+	}
+	if ws.output != nil {
+		ws.output.removeWorkspace(ws)
+	}
+	for i, w := range wm.workspaces {
+		if w == ws {
+			wm.workspaces = append(wm.workspaces[:i], wm.workspaces[i+1:]...)
+			return
+		}
+	}
+}
+
+// renameWorkspace changes the name of the workspace, along with the placeholders reserved on it and the outputs
+// remembering it as their previous workspace
+func (wm *WM) renameWorkspace(ws *workspace, name string) error {
+	if name == "" {
+		return fmt.Errorf("empty workspace name")
+	}
+	if other := wm.findWorkspace(func(w *workspace) bool { return w.name == name }); other != nil && other != ws {
+		return fmt.Errorf("workspace %q already exists", name)
+	}
+	for _, ph := range wm.placeholders {
+		if ph.workspace == ws.name {
+			ph.workspace = name
+		}
+	}
+	for _, o := range wm.outputs {
+		if o.prevWs == ws.name {
+			o.prevWs = name
+		}
+	}
+	ws.setName(name)
+	if ws.output != nil {
+		ws.output.sortWorkspaces()
+	}
+	return wm.updateDesktopHints()
+}
+
+// moveWorkspaceToOutput moves the workspace together with all its frames to the output, showing it there.
+// The output left by the workspace shows its previous workspace instead, or a new one
+func (wm *WM) moveWorkspaceToOutput(ws *workspace, o *output) error {
+	src := ws.output
+	if src == o {
+		return nil
+	}
+	if src.activeWs == ws {
+		next := src.findWorkspace(func(w *workspace) bool { return w != ws && w.name == src.prevWs })
+		if next == nil {
+			next = src.findWorkspace(func(w *workspace) bool { return w != ws })
+		}
+		if next == nil {
+			next = wm.newWorkspace(wm.freeWorkspaceName())
+			if err := src.addWorkspace(next); err != nil {
+				return err
+			}
+		}
+		if err := next.show(); err != nil {
+			return fmt.Errorf("failed to show next workspace: %v", err)
+		}
+		src.activeWs = next
+	}
+	src.removeWorkspace(ws)
+	// the workspace shown before is either the one left or the one now shown instead
+	if src.prevWs == ws.name || src.prevWs == src.activeWs.name {
+		src.prevWs = ""
+	}
+
+	// floating frames keep their position relative to the output
+	for _, f := range ws.floating {
+		f.floatGeom.X += o.geom.X - src.geom.X
+		f.floatGeom.Y += o.geom.Y - src.geom.Y
+	}
+	if err := o.addWorkspace(ws); err != nil {
+		return err
+	}
+	if err := o.switchWorkspace(ws); err != nil {
+		return err
+	}
+	if err := wm.renderOutput(src); err != nil {
+		return fmt.Errorf("failed to render previous output: %v", err)
+	}
+	if err := wm.renderOutput(o); err != nil {
+		return fmt.Errorf("failed to render next output: %v", err)
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		return fmt.Errorf("failed to update desktop hints: %v", err)
+	}
+	if f := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin }); f != nil && f.workspace() == ws {
+		return wm.warpPointerToFrame(f)
+	}
+	return nil
+}
+
+// outputInDirection returns the nearest output to the left or right of the given one, or nil if there is none
+func (wm *WM) outputInDirection(o *output, dir MoveDirection) *output {
+	var nearest *output
+	for _, other := range wm.outputs {
+		dx := int(other.geom.X) - int(o.geom.X)
+		if other == o || (dir == MoveLeft && dx >= 0) || (dir == MoveRight && dx <= 0) {
+			continue
+		}
+		if nearest == nil || abs(dx) < abs(int(nearest.geom.X)-int(o.geom.X)) {
+			nearest = other
+		}
+	}
+	return nearest
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
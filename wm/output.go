@@ -14,14 +14,21 @@ type dockArea uint8
 const (
 	dockAreaTop    dockArea = 0
 	dockAreaBottom dockArea = 1
+	dockAreaLeft   dockArea = 2
+	dockAreaRight  dockArea = 3
 )
 
 type output struct {
 	xc         *x11.Connection
+	name       string
 	geom       client.Geom
 	workspaces []*workspace
 	activeWs   *workspace
-	dockAreas  [2][]*frame
+	prevWs     string // name of the workspace shown before the active one
+	dockAreas  [4][]*frame
+
+	// desktopWins are the windows drawing the desktop (e.g. its icons), kept below the workspaces
+	desktopWins []*frame
 }
 
 // newOutput creates a new output from the given geometry
@@ -34,9 +41,7 @@ func newOutput(xc *x11.Connection, geom client.Geom) *output {
 func (o *output) addWorkspace(ws *workspace) error {
 	ws.setOutput(o)
 	o.workspaces = append(o.workspaces, ws)
-	sort.Slice(o.workspaces, func(i, j int) bool {
-		return o.workspaces[i].id < o.workspaces[j].id
-	})
+	o.sortWorkspaces()
 	if o.activeWs == nil {
 		o.activeWs = ws
 		return ws.show()
@@ -57,13 +62,24 @@ func (o *output) switchWorkspace(next *workspace) error {
 	if err := o.activeWs.hide(); err != nil {
 		return fmt.Errorf("failed to hide previous workspace: %v", err)
 	}
-	if len(o.activeWs.columns) == 0 {
-		o.removeWorkspace(o.activeWs)
-	}
+	o.prevWs = o.activeWs.name
 	o.activeWs = next
 	return nil
 }
 
+// sortWorkspaces orders the workspaces by their numbers, keeping the rest in the order of their creation
+func (o *output) sortWorkspaces() {
+	sort.SliceStable(o.workspaces, func(i, j int) bool {
+		return workspaceLess(o.workspaces[i], o.workspaces[j])
+	})
+}
+
+// contains reports whether the point lies within the output
+func (o *output) contains(x, y int16) bool {
+	return x >= o.geom.X && int(x) < int(o.geom.X)+int(o.geom.W) &&
+		y >= o.geom.Y && int(y) < int(o.geom.Y)+int(o.geom.H)
+}
+
 func (o *output) findWorkspace(predicate func(*workspace) bool) *workspace {
 	for _, ws := range o.workspaces {
 		if predicate(ws) {
@@ -83,55 +99,63 @@ func (o *output) removeWorkspace(ws *workspace) {
 	}
 }
 
-// addDock appends the frame as a dock of this output
-func (o *output) addDock(f *frame) error {
-	struts, err := o.xc.GetWindowStruts(f.cli.Window())
-	if err != nil {
-		return fmt.Errorf("failed to get struts: %v", err)
-	}
-	var area dockArea
-	switch {
-	case struts.Top > struts.Bottom:
-		area = dockAreaTop
-		f.height = uint16(struts.Top)
-	case struts.Bottom > struts.Top:
-		area = dockAreaBottom
-		f.height = uint16(struts.Bottom)
-	default:
-		return fmt.Errorf("could not determine the dock position")
-	}
+// addDock appends the frame as a dock of this output, at the given edge
+func (o *output) addDock(f *frame, area dockArea) error {
 	o.dockAreas[area] = append(o.dockAreas[area], f)
-	// TODO map the dock
-	o.updateTiling()
 	return f.cli.Map()
 }
 
-// dockHeight returns the height of the entire dock area
-func (o *output) dockHeight(area dockArea) uint16 {
-	var height uint16
+// addDesktopWin adds the desktop window to this output, leaving it where it placed itself
+func (o *output) addDesktopWin(f *frame) error {
+	o.desktopWins = append(o.desktopWins, f)
+	return f.cli.Map()
+}
+
+// dockGeom returns the part of the area reserved by the dock that lies within this output
+func (o *output) dockGeom(f *frame) client.Geom {
+	return intersect(f.strut, o.geom)
+}
+
+// reserved returns the size of the area reserved by the docks at the edge of this output
+func (o *output) reserved(area dockArea) uint16 {
+	var size uint16
 	for _, f := range o.dockAreas[area] {
-		height += f.height
+		geom := o.dockGeom(f)
+		s := geom.H
+		if area == dockAreaLeft || area == dockAreaRight {
+			s = geom.W
+		}
+		if s > size {
+			size = s
+		}
 	}
-	return height
+	return size
 }
 
 func (o *output) workspaceArea() client.Geom {
-	top := o.dockHeight(dockAreaTop)
-	bottom := o.dockHeight(dockAreaBottom)
+	top := o.reserved(dockAreaTop)
+	bottom := o.reserved(dockAreaBottom)
+	left := o.reserved(dockAreaLeft)
+	right := o.reserved(dockAreaRight)
 	return client.Geom{
-		X: o.geom.X,
+		X: o.geom.X + int16(left),
 		Y: o.geom.Y + int16(top),
-		W: o.geom.W,
-		H: o.geom.H - top - bottom,
+		W: uint16(maxInt(int(o.geom.W)-int(left)-int(right), 0)),
+		H: uint16(maxInt(int(o.geom.H)-int(top)-int(bottom), 0)),
 	}
 }
 
 func (o *output) deleteFrame(frm *frame) bool {
+	for i, f := range o.desktopWins {
+		if frm == f {
+			o.desktopWins = append(o.desktopWins[:i], o.desktopWins[i+1:]...)
+			return true
+		}
+	}
 	for area := range o.dockAreas {
 		for i, f := range o.dockAreas[area] {
 			if frm == f {
 				o.dockAreas[area] = append(o.dockAreas[area][:i], o.dockAreas[area][i+1:]...)
-				o.updateTiling()
 				return true
 			}
 		}
@@ -143,9 +167,3 @@ func (o *output) deleteFrame(frm *frame) bool {
 	}
 	return false
 }
-
-func (o *output) updateTiling() {
-	for _, ws := range o.workspaces {
-		ws.updateTiling()
-	}
-}
@@ -0,0 +1,82 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+func TestOutputInDirection(t *testing.T) {
+	left := newOutput(nil, client.Geom{X: 0, W: 1920, H: 1080})
+	middle := newOutput(nil, client.Geom{X: 1920, W: 2560, H: 1440})
+	right := newOutput(nil, client.Geom{X: 4480, W: 1920, H: 1080})
+	wm := &WM{outputs: []*output{middle, right, left}}
+
+	tests := []struct {
+		from *output
+		dir  MoveDirection
+		want *output
+	}{
+		{middle, MoveLeft, left},
+		{middle, MoveRight, right},
+		{right, MoveLeft, middle},
+		{left, MoveLeft, nil},
+		{right, MoveRight, nil},
+	}
+	for _, tt := range tests {
+		if got := wm.outputInDirection(tt.from, tt.dir); got != tt.want {
+			t.Errorf("outputInDirection(%v, %d) = %v, want %v", tt.from.geom, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestInitialWorkspaceName(t *testing.T) {
+	wm := &WM{config: Config{WorkspaceOutputs: map[string]string{
+		"10": "DP-1", "mail": "DP-1", "6:web": "DP-1", "1": "eDP-1",
+	}}}
+	dp := newOutput(nil, client.Geom{})
+	dp.name = "DP-1"
+	hdmi := newOutput(nil, client.Geom{})
+	hdmi.name = "HDMI-1"
+
+	if name := wm.initialWorkspaceName(dp); name != "6:web" {
+		t.Errorf("got %q for DP-1, want %q", name, "6:web")
+	}
+	wm.newWorkspace("2")
+	if name := wm.initialWorkspaceName(hdmi); name != "1" {
+		t.Errorf("got %q for HDMI-1, want %q", name, "1")
+	}
+	if out, ok := wm.assignedOutput(newWorkspace("1:term", workspaceConfig{})); !ok || out != "eDP-1" {
+		t.Errorf("got assigned output %q, want %q", out, "eDP-1")
+	}
+}
+
+func TestWorkspaceAreaStruts(t *testing.T) {
+	screen := client.Geom{W: 3840, H: 1080}
+	left := newOutput(nil, client.Geom{X: 0, W: 1920, H: 1080})
+	right := newOutput(nil, client.Geom{X: 1920, W: 1920, H: 1080})
+
+	// a partial-width panel at the top of the right output, a side panel at the left edge of the screen
+	// and a side panel at the left edge of the right output, measured from the left edge of the screen
+	struts := []x11.Struts{
+		{Top: 30, TopStartX: 1920, TopEndX: 3839},
+		{Left: 50, LeftStartY: 0, LeftEndY: 1079},
+		{Left: 1920 + 50, LeftStartY: 0, LeftEndY: 1079},
+	}
+	for _, s := range struts {
+		area, strut := dockStrut(&s, screen)
+		f := &frame{strut: strut}
+		if geom := right.dockGeom(f); geom.W > 0 && geom.H > 0 {
+			right.dockAreas[area] = append(right.dockAreas[area], f)
+		} else {
+			left.dockAreas[area] = append(left.dockAreas[area], f)
+		}
+	}
+	if got, want := left.workspaceArea(), (client.Geom{X: 50, Y: 0, W: 1870, H: 1080}); got != want {
+		t.Errorf("got left area %+v, want %+v", got, want)
+	}
+	if got, want := right.workspaceArea(), (client.Geom{X: 1970, Y: 30, W: 1870, H: 1050}); got != want {
+		t.Errorf("got right area %+v, want %+v", got, want)
+	}
+}
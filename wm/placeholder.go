@@ -0,0 +1,162 @@
+package wm
+
+import (
+	"time"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// placeholderTimeout is how long the places of the windows of a restored session are kept. The placeholders
+// of the applications that failed to start don't take over the windows opened later this way
+const placeholderTimeout = time.Minute
+
+// placeholder reserves a place in a workspace for a window that is yet to be mapped. The first new window
+// matching its criteria takes that place instead of being placed according to the rules
+type placeholder struct {
+	matcher    *matcher
+	workspace  string
+	slot       *slotColumn // nil for floating placeholders
+	row        int
+	weight     float64 // share of the column height, relative to the other frames of the layout
+	geom       client.Geom
+	fullscreen bool
+	noTitlebar bool
+	expires    time.Time // zero if the placeholder is kept until a window takes it
+}
+
+// slotColumn is a column of a layout being filled with windows, or a container nested in one. The actual
+// container is only created once the first window takes one of its places
+type slotColumn struct {
+	layout     *slotLayout
+	parent     *slotColumn // the slot this one is nested in, nil for the columns of the layout
+	index      int         // position in the layout, or the row in the parent slot
+	horizontal bool
+	weight     float64 // share of the workspace width, or of the parent container
+	col        *column
+	rows       map[node]int
+}
+
+// slotLayout groups the columns of a layout loaded into a single workspace
+type slotLayout struct {
+	columns []*slotColumn
+}
+
+// addColumn appends a column of the given share of the workspace width to the layout
+func (sl *slotLayout) addColumn(weight float64) *slotColumn {
+	sc := &slotColumn{layout: sl, index: len(sl.columns), weight: weight, rows: make(map[node]int)}
+	sl.columns = append(sl.columns, sc)
+	return sc
+}
+
+// addSplit adds a nested container of the given share of this one, taking the place of the given row
+func (sc *slotColumn) addSplit(row int, horizontal bool, weight float64) *slotColumn {
+	return &slotColumn{
+		layout:     sc.layout,
+		parent:     sc,
+		index:      row,
+		horizontal: horizontal,
+		weight:     weight,
+		rows:       make(map[node]int),
+	}
+}
+
+// column returns the actual container in the workspace, creating it between the already existing columns
+// of the same layout, or among the items of the parent container, if needed
+func (sc *slotColumn) column(ws *workspace) *column {
+	if sc.parent != nil {
+		p := sc.parent.column(ws)
+		if sc.col != nil && sc.col.parent == p && p.index(sc.col) >= 0 {
+			return sc.col
+		}
+		sc.col = &column{ws: ws, horizontal: sc.horizontal}
+		sc.rows = make(map[node]int)
+		sc.parent.insert(sc.col, sc.index)
+		if sc.weight > 0 {
+			setShare(p.weights(), p.index(sc.col), sc.weight)
+		}
+		return sc.col
+	}
+	if sc.col != nil && ws.findColumnIndex(func(c *column) bool { return c == sc.col }) >= 0 {
+		return sc.col
+	}
+	i := len(ws.columns)
+	for _, other := range sc.layout.columns {
+		if other == sc || other.col == nil {
+			continue
+		}
+		j := ws.findColumnIndex(func(c *column) bool { return c == other.col })
+		if j < 0 {
+			continue
+		}
+		if other.index < sc.index {
+			i = j + 1
+		} else {
+			i = j
+			break
+		}
+	}
+	sc.col = ws.insertColumn(i)
+	sc.rows = make(map[node]int)
+	if sc.weight > 0 {
+		ws.setColumnWeight(sc.col, sc.weight)
+	}
+	return sc.col
+}
+
+// addFrame puts the frame in the container, below the items taking the places of the preceding rows
+func (sc *slotColumn) addFrame(ws *workspace, f *frame, row int) {
+	sc.column(ws)
+	sc.insert(f, row)
+}
+
+// insert adds the item to the actual container, below the items taking the places of the preceding rows
+func (sc *slotColumn) insert(n node, row int) {
+	i := len(sc.col.nodes)
+	for j, other := range sc.col.nodes {
+		if r, ok := sc.rows[other]; ok && r > row {
+			i = j
+			break
+		}
+	}
+	sc.col.insertNode(n, i)
+	sc.rows[n] = row
+}
+
+// takePlaceholder removes and returns the first placeholder matching the window, dropping the expired ones
+func (wm *WM) takePlaceholder(info windowInfo) *placeholder {
+	now := time.Now()
+	kept := wm.placeholders[:0]
+	var found *placeholder
+	for _, ph := range wm.placeholders {
+		switch {
+		case !ph.expires.IsZero() && now.After(ph.expires):
+		case found == nil && ph.matcher.match(info):
+			found = ph
+		default:
+			kept = append(kept, ph)
+		}
+	}
+	wm.placeholders = kept
+	return found
+}
+
+// fillPlaceholder puts the frame in the place reserved by the placeholder and returns the workspace
+func (wm *WM) fillPlaceholder(f *frame, ph *placeholder) (*workspace, error) {
+	ws, err := wm.ensureWorkspace(ph.workspace)
+	if err != nil {
+		return nil, err
+	}
+	f.cli.SetTitlebar(!ph.noTitlebar)
+	f.fullscreen = ph.fullscreen
+	if err := wm.updateWindowState(f); err != nil {
+		return nil, err
+	}
+	if ph.slot == nil {
+		return ws, ws.addFloatingFrame(f, ph.geom)
+	}
+	ph.slot.addFrame(ws, f, ph.row)
+	if ph.weight > 0 {
+		f.col.setFrameWeight(f, ph.weight)
+	}
+	return ws, ws.mapFrame(f)
+}
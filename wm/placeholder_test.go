@@ -0,0 +1,129 @@
+package wm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func newTestWorkspace() *workspace {
+	o := newOutput(nil, client.Geom{X: 0, Y: 0, W: 1000, H: 800})
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.setOutput(o)
+	o.workspaces = append(o.workspaces, ws)
+	o.activeWs = ws
+	return ws
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	ws := newTestWorkspace()
+	tmpl := layoutTemplate{Columns: []templateColumn{
+		{Width: 0.6, Frames: []templateFrame{{Criteria: Criteria{Class: "^Firefox$"}}}},
+		{Width: 0.4, Frames: []templateFrame{
+			{Height: 0.75, Criteria: Criteria{Class: "^Alacritty$"}},
+			{Height: 0.25, Criteria: Criteria{Class: "^Spotify$"}},
+		}},
+	}}
+	placeholders, err := tmpl.placeholders(ws)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(placeholders) != 3 {
+		t.Fatalf("got %d placeholders, want 3", len(placeholders))
+	}
+	if w := placeholders[0].slot.weight; w != 0.6 {
+		t.Errorf("got column weight = %v, want 0.6", w)
+	}
+	if h := placeholders[2].weight; h != 0.25 {
+		t.Errorf("got frame weight = %v, want 0.25", h)
+	}
+	if !placeholders[1].matcher.match(windowInfo{class: "Alacritty"}) {
+		t.Errorf("expected the placeholder to match")
+	}
+}
+
+func TestSlotColumnOrder(t *testing.T) {
+	ws := newTestWorkspace()
+	existing := &frame{}
+	ws.createColumn(false).addFrame(existing, nil)
+
+	sl := &slotLayout{}
+	left, right := sl.addColumn(0), sl.addColumn(0)
+	a, b, c := &frame{}, &frame{}, &frame{}
+	right.addFrame(ws, b, 1)
+	left.addFrame(ws, a, 0)
+	right.addFrame(ws, c, 0)
+
+	if len(ws.columns) != 3 {
+		t.Fatalf("got %d columns, want 3", len(ws.columns))
+	}
+	if ws.columns[0] != existing.col || ws.columns[1] != a.col || ws.columns[2] != b.col {
+		t.Errorf("the columns are not in the layout order")
+	}
+	if frames := ws.columns[2].frames(); frames[0] != c || frames[1] != b {
+		t.Errorf("the frames are not in the layout order")
+	}
+}
+
+func TestTakePlaceholder(t *testing.T) {
+	newPlaceholder := func(class string) *placeholder {
+		m, err := newMatcher(Criteria{Class: class})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return &placeholder{matcher: m}
+	}
+	first, second := newPlaceholder("^Firefox$"), newPlaceholder("^Firefox$")
+	other := newPlaceholder("^Alacritty$")
+	wm := &WM{placeholders: []*placeholder{first, other, second}}
+
+	if ph := wm.takePlaceholder(windowInfo{class: "Spotify"}); ph != nil {
+		t.Errorf("got a placeholder for a window not matching any")
+	}
+	if ph := wm.takePlaceholder(windowInfo{class: "Firefox"}); ph != first {
+		t.Errorf("got a wrong placeholder")
+	}
+	if len(wm.placeholders) != 2 || wm.placeholders[0] != other || wm.placeholders[1] != second {
+		t.Errorf("the placeholder taken is not removed")
+	}
+}
+
+func TestTakePlaceholderExpired(t *testing.T) {
+	m, err := newMatcher(Criteria{Class: "^Firefox$"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expired := &placeholder{matcher: m, expires: time.Now().Add(-time.Second)}
+	kept := &placeholder{matcher: m}
+	wm := &WM{placeholders: []*placeholder{expired, kept}}
+
+	if ph := wm.takePlaceholder(windowInfo{class: "Alacritty"}); ph != nil {
+		t.Errorf("got a placeholder for a window not matching any")
+	}
+	if len(wm.placeholders) != 1 || wm.placeholders[0] != kept {
+		t.Fatalf("expired placeholder not dropped")
+	}
+	if ph := wm.takePlaceholder(windowInfo{class: "Firefox"}); ph != kept {
+		t.Errorf("got a wrong placeholder")
+	}
+}
+
+func TestSlotNestedOrder(t *testing.T) {
+	ws := newTestWorkspace()
+	sl := &slotLayout{}
+	sc := sl.addColumn(0)
+	split := sc.addSplit(1, true, 0.5)
+	a, b, c := &frame{}, &frame{}, &frame{}
+	split.addFrame(ws, c, 1)
+	sc.addFrame(ws, a, 0)
+	split.addFrame(ws, b, 0)
+
+	col := ws.columns[0]
+	if len(col.nodes) != 2 || col.nodes[0] != a || col.nodes[1] != split.col {
+		t.Fatalf("the nested container is not placed after the frame of the preceding row")
+	}
+	if !split.col.horizontal || b.col != split.col || split.col.nodes[0] != b || split.col.nodes[1] != c {
+		t.Errorf("the frames are not placed side by side in the layout order")
+	}
+}
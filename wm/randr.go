@@ -0,0 +1,158 @@
+package wm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// initOutputs creates an output for each monitor found with RandR, or a single output covering the entire
+// screen if RandR is not available
+func (wm *WM) initOutputs() {
+	outputs, err := wm.xc.GetOutputs()
+	if err != nil {
+		log.Println("Failed to get outputs:", err)
+	}
+	for _, out := range outputs {
+		o := newOutput(wm.xc, client.Geom{X: out.X, Y: out.Y, W: out.W, H: out.H})
+		o.name = out.Name
+		wm.outputs = append(wm.outputs, o)
+	}
+	if len(wm.outputs) == 0 {
+		wm.outputs = append(wm.outputs, newOutput(wm.xc, client.Geom{
+			X: 0, Y: 0,
+			W: wm.xc.Screen().WidthInPixels,
+			H: wm.xc.Screen().HeightInPixels,
+		}))
+	}
+}
+
+// assignedOutput returns the name of the output on which the workspace is to be placed according to the config
+func (wm *WM) assignedOutput(ws *workspace) (string, bool) {
+	if name, ok := wm.config.WorkspaceOutputs[ws.name]; ok {
+		return name, true
+	}
+	if ws.num >= 0 {
+		name, ok := wm.config.WorkspaceOutputs[strconv.Itoa(ws.num)]
+		return name, ok
+	}
+	return "", false
+}
+
+// workspaceOutput returns the output on which a new workspace is to be placed: the one it is assigned to
+// if connected, otherwise the primary output for assigned workspaces and the current output for the rest
+func (wm *WM) workspaceOutput(ws *workspace) *output {
+	name, ok := wm.assignedOutput(ws)
+	if !ok {
+		return wm.currentOutput()
+	}
+	if o := wm.findOutput(func(o *output) bool { return o.name == name }); o != nil {
+		return o
+	}
+	return wm.outputs[0]
+}
+
+// initialWorkspaceName returns the name of the workspace shown first on the output: the lowest one assigned
+// to it in the config, or the lowest free number
+func (wm *WM) initialWorkspaceName(o *output) string {
+	var assigned []*workspace
+	for name, out := range wm.config.WorkspaceOutputs {
+		if out == o.name && wm.lookupWorkspace(name) == nil {
+			assigned = append(assigned, newWorkspace(name, workspaceConfig{}))
+		}
+	}
+	if len(assigned) == 0 {
+		return wm.freeWorkspaceName()
+	}
+	sort.Slice(assigned, func(i, j int) bool { return assigned[i].name < assigned[j].name })
+	sort.SliceStable(assigned, func(i, j int) bool { return workspaceLess(assigned[i], assigned[j]) })
+	return assigned[0].name
+}
+
+// updateOutputs synchronises the outputs with the monitors reported by RandR. The workspaces of removed outputs
+// are moved to the primary output, and the workspaces assigned to the added outputs are moved back to them
+func (wm *WM) updateOutputs() error {
+	outs, err := wm.xc.GetOutputs()
+	if err != nil {
+		return fmt.Errorf("failed to get outputs: %v", err)
+	}
+	if len(outs) == 0 {
+		return nil
+	}
+	var outputs, added []*output
+	for _, out := range outs {
+		o := wm.findOutput(func(o *output) bool { return o.name == out.Name })
+		if o == nil {
+			o = newOutput(wm.xc, client.Geom{})
+			o.name = out.Name
+			added = append(added, o)
+		}
+		o.geom = client.Geom{X: out.X, Y: out.Y, W: out.W, H: out.H}
+		outputs = append(outputs, o)
+	}
+	primary := outputs[0]
+	var removed []*output
+	for _, o := range wm.outputs {
+		if !containsOutput(outputs, o) {
+			removed = append(removed, o)
+		}
+	}
+	wm.outputs = outputs
+	if containsOutput(removed, wm.lastOutput) {
+		wm.lastOutput = primary
+	}
+	for _, o := range removed {
+		for area := range o.dockAreas {
+			for _, f := range o.dockAreas[area] {
+				next := wm.dockOutput(f)
+				next.dockAreas[area] = append(next.dockAreas[area], f)
+			}
+		}
+		primary.desktopWins = append(primary.desktopWins, o.desktopWins...)
+		for _, ws := range o.workspaces {
+			ws.output = nil
+			if err := ws.hide(); err != nil {
+				log.Println("Failed to hide workspace:", err)
+			}
+			if len(ws.frames()) == 0 {
+				wm.pruneWorkspace(ws)
+				continue
+			}
+			if err := primary.addWorkspace(ws); err != nil {
+				return err
+			}
+		}
+	}
+	for _, o := range added {
+		for _, ws := range append([]*workspace(nil), wm.workspaces...) {
+			if name, ok := wm.assignedOutput(ws); ok && name == o.name && ws.output != nil {
+				if err := wm.moveWorkspaceToOutput(ws, o); err != nil {
+					return err
+				}
+			}
+		}
+		if o.activeWs == nil {
+			if err := o.addWorkspace(wm.newWorkspace(wm.initialWorkspaceName(o))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, o := range wm.outputs {
+		if err := wm.renderOutput(o); err != nil {
+			return err
+		}
+	}
+	return wm.updateDesktopHints()
+}
+
+func containsOutput(outputs []*output, o *output) bool {
+	for _, other := range outputs {
+		if other == o {
+			return true
+		}
+	}
+	return false
+}
@@ -7,11 +7,10 @@ import (
 
 func (wm *WM) renderOutput(o *output) error {
 	var err error
-	if e := wm.renderDock(o, dockAreaTop); e != nil {
-		err = e
-	}
-	if e := wm.renderDock(o, dockAreaBottom); e != nil {
-		err = e
+	for area := range o.dockAreas {
+		if e := wm.renderDock(o, dockArea(area)); e != nil {
+			err = e
+		}
 	}
 	if e := wm.renderWorkspace(o.activeWs); e != nil {
 		err = e
@@ -21,63 +20,146 @@ func (wm *WM) renderOutput(o *output) error {
 
 func (wm *WM) renderDock(o *output, area dockArea) error {
 	var err error
-	var y int16
-	switch area {
-	case dockAreaTop:
-		y = o.geom.Y
-	case dockAreaBottom:
-		y = int16(o.geom.H - o.dockHeight(area))
-	}
 	for _, f := range o.dockAreas[area] {
-		geom := client.Geom{
-			X: o.geom.X,
-			Y: y,
-			W: o.geom.W,
-			H: f.height,
+		// docks that don't reserve any space are left where they placed themselves
+		geom := o.dockGeom(f)
+		if geom.W == 0 || geom.H == 0 {
+			continue
+		}
+		if e := wm.renderFrame(f, geom); e != nil {
+			err = e
 		}
-		err = wm.renderFrame(f, geom)
-		y += int16(geom.H)
 	}
 	return err
 }
 
 func (wm *WM) renderWorkspace(ws *workspace) error {
+	err := wm.renderTiling(ws)
+	for _, f := range ws.floating {
+		if f.fullscreen {
+			continue
+		}
+		if e := wm.renderFrame(f, f.floatGeom); e != nil {
+			err = e
+		}
+	}
+	for _, f := range ws.frames() {
+		if !f.fullscreen || f.hidden {
+			continue
+		}
+		if e := wm.renderFrame(f, ws.output.geom); e != nil {
+			err = e
+		}
+	}
+	if e := wm.restack(); e != nil {
+		err = e
+	}
+	return err
+}
+
+func (wm *WM) renderTiling(ws *workspace) error {
 	var err error
+	if ws.scrolling {
+		return wm.renderStrip(ws)
+	}
 	if f := ws.singleFrame(); f != nil {
+		if f.fullscreen {
+			return nil
+		}
 		return wm.renderFrame(f, ws.fullArea())
 	}
 	a := ws.area()
+	weights := make([]float64, len(ws.columns))
+	for i, col := range ws.columns {
+		weights[i] = col.weight
+	}
 	x := a.X
-	for _, col := range ws.columns {
+	for i, w := range splitSize(a.W, weights) {
 		geom := client.Geom{
 			X: x,
 			Y: a.Y,
-			W: col.width,
+			W: w,
 			H: a.H,
 		}
+		if e := wm.renderColumn(ws.columns[i], geom); e != nil {
+			err = e
+		}
+		x += int16(w)
+	}
+	return err
+}
+
+// renderStrip places the columns of a scrolling workspace side by side at their own widths. The frames
+// of the columns scrolled entirely out of view stay mapped, but off the screen
+func (wm *WM) renderStrip(ws *workspace) error {
+	var err error
+	a := ws.area()
+	if f := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin }); f != nil && f.workspace() == ws && f.tiled() {
+		ws.scrollTo(f.topColumn())
+	}
+	if max := ws.stripWidth() - int(a.W); ws.scroll > max {
+		ws.scroll = max
+	}
+	if ws.scroll < 0 {
+		ws.scroll = 0
+	}
+	screen := wm.screenGeom()
+	x := int(a.X) - ws.scroll
+	for _, col := range ws.columns {
+		w := ws.columnWidth(col)
+		geom := client.Geom{X: int16(x), Y: a.Y, W: w, H: a.H}
+		// the columns out of view are moved past the edges of the screen, where no other output shows them
+		switch {
+		case x+int(w) <= int(a.X):
+			geom.X = screen.X - int16(w)
+		case x >= int(a.X)+int(a.W):
+			geom.X = screen.X + int16(screen.W)
+		}
 		if e := wm.renderColumn(col, geom); e != nil {
 			err = e
 		}
-		x += int16(col.width)
+		x += int(w)
 	}
 	return err
 }
 
+// renderColumn divides the area between the items of the column, rendering the nested containers recursively
 func (wm *WM) renderColumn(col *column, geom client.Geom) error {
 	var err error
-	y := geom.Y
 	gap := wm.config.InnerGap
-	for _, f := range col.frames {
-		fg := client.Geom{
-			X: geom.X + int16(gap),
-			Y: y + int16(gap),
-			W: geom.W - gap*2,
-			H: f.height - gap*2,
+	weights := make([]float64, len(col.nodes))
+	for i, n := range col.nodes {
+		weights[i] = *n.share()
+	}
+	size, pos := geom.H, geom.Y
+	if col.horizontal {
+		size, pos = geom.W, geom.X
+	}
+	for i, s := range splitSize(size, weights) {
+		cell := client.Geom{X: geom.X, Y: pos, W: geom.W, H: s}
+		if col.horizontal {
+			cell = client.Geom{X: pos, Y: geom.Y, W: s, H: geom.H}
 		}
-		if e := wm.renderFrame(f, fg); e != nil {
-			err = e
+		pos += int16(s)
+		switch n := col.nodes[i].(type) {
+		case *column:
+			if e := wm.renderColumn(n, cell); e != nil {
+				err = e
+			}
+		case *frame:
+			if n.fullscreen {
+				continue
+			}
+			fg := client.Geom{
+				X: cell.X + int16(gap),
+				Y: cell.Y + int16(gap),
+				W: cell.W - gap*2,
+				H: cell.H - gap*2,
+			}
+			if e := wm.renderFrame(n, fg); e != nil {
+				err = e
+			}
 		}
-		y += int16(f.height)
 	}
 	return err
 }
@@ -87,15 +169,18 @@ func (wm *WM) renderFrame(f *frame, geom client.Geom) error {
 		return nil
 	}
 	f.cli.SetGeom(geom)
+	if err := wm.updateFrameExtents(f); err != nil {
+		return err
+	}
 	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
 	parentVals := []uint32{uint32(geom.X), uint32(geom.Y), uint32(geom.W), uint32(geom.H)}
-	clientVals := parentVals
+	cg := wm.clientGeom(f, geom)
+	clientVals := []uint32{uint32(geom.X + cg.X), uint32(geom.Y + cg.Y), uint32(cg.W), uint32(cg.H)}
 	if f.cli.Parent() != 0 {
 		if err := xproto.ConfigureWindowChecked(wm.xc.X(), f.cli.Parent(), mask, parentVals).Check(); err != nil {
 			return err
 		}
-		d := wm.getFrameDecorations(f)
-		clientVals = []uint32{d.Left, d.Top, uint32(geom.W) - d.Left - d.Right, uint32(geom.H) - d.Top - d.Bottom}
+		clientVals[0], clientVals[1] = uint32(cg.X), uint32(cg.Y)
 	}
 	if err := xproto.ConfigureWindowChecked(wm.xc.X(), f.cli.Window(), mask, clientVals).Check(); err != nil {
 		return err
@@ -109,16 +194,13 @@ func (wm *WM) renderFrame(f *frame, geom client.Geom) error {
 func (wm *WM) configureNotify(f *frame) error {
 	// Hack for Java applications as described here:
 	// https://stackoverflow.com/questions/31646544/xlib-reparenting-a-java-window-with-popups-properly-translated
-	// TODO: when window decorations are added, this should change to include them
 	geom := f.cli.Geom()
-	if f.cli.Parent() != 0 {
-		d := wm.getFrameDecorations(f)
-		geom = client.Geom{
-			X: geom.X + int16(d.Left),
-			Y: geom.Y + int16(d.Top),
-			W: geom.W - uint16(d.Left-d.Right),
-			H: geom.H - uint16(d.Top-d.Bottom),
-		}
+	cg := wm.clientGeom(f, geom)
+	geom = client.Geom{
+		X: geom.X + cg.X,
+		Y: geom.Y + cg.Y,
+		W: cg.W,
+		H: cg.H,
 	}
 	ev := xproto.ConfigureNotifyEvent{
 		Event:            f.cli.Window(),
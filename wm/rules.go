@@ -0,0 +1,170 @@
+package wm
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+// Toggle is a tri-state value that either leaves a property of a window unchanged, or forces it on or off
+type Toggle uint8
+
+const (
+	Unset Toggle = iota
+	On
+	Off
+)
+
+// Criteria select windows by their properties. String values are regular expressions, empty values match
+// any window
+type Criteria struct {
+	Class    string      `json:"class,omitempty"`    // class part of WM_CLASS
+	Instance string      `json:"instance,omitempty"` // instance part of WM_CLASS
+	Role     string      `json:"role,omitempty"`     // WM_WINDOW_ROLE
+	Title    string      `json:"title,omitempty"`    // _NET_WM_NAME
+	Type     client.Type `json:"type,omitempty"`     // type of the window (TypeUnknown matches any)
+}
+
+// Rule decides where and how the windows matching its criteria are placed when they are mapped.
+// When several rules match a window, all of them are applied in order
+type Rule struct {
+	Criteria
+
+	Workspace  string `json:"workspace,omitempty"` // Name of the workspace to put the window on
+	Output     string `json:"output,omitempty"`    // Name of the output whose active workspace receives the window
+	Column     int    `json:"column,omitempty"`    // 1-based index of the column the window is added to (0 means the last column)
+	Width      uint16 `json:"width,omitempty"`     // Width of the window (or its column when tiled), in pixels
+	Height     uint16 `json:"height,omitempty"`    // Height of the window, in pixels
+	Floating   Toggle `json:"floating,omitempty"`
+	Titlebar   Toggle `json:"titlebar,omitempty"`
+	Fullscreen Toggle `json:"fullscreen,omitempty"`
+}
+
+// windowInfo holds the properties of a window that can be matched against Criteria
+type windowInfo struct {
+	class, instance, role, title string
+	typ                          client.Type
+}
+
+type matcher struct {
+	class, instance, role, title *regexp.Regexp
+	typ                          client.Type
+}
+
+type compiledRule struct {
+	rule    Rule
+	matcher *matcher
+}
+
+// placement is the result of applying all matching rules to a window
+type placement struct {
+	workspace     string
+	output        string
+	column        int
+	width, height uint16
+	floating      Toggle
+	titlebar      Toggle
+	fullscreen    Toggle
+}
+
+func newMatcher(c Criteria) (*matcher, error) {
+	m := &matcher{typ: c.Type}
+	for _, field := range []struct {
+		expr string
+		re   **regexp.Regexp
+	}{
+		{c.Class, &m.class},
+		{c.Instance, &m.instance},
+		{c.Role, &m.role},
+		{c.Title, &m.title},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(field.expr)
+		if err != nil {
+			return nil, err
+		}
+		*field.re = re
+	}
+	return m, nil
+}
+
+func (m *matcher) match(info windowInfo) bool {
+	if m.typ != client.TypeUnknown && m.typ != info.typ {
+		return false
+	}
+	for _, field := range []struct {
+		re    *regexp.Regexp
+		value string
+	}{
+		{m.class, info.class},
+		{m.instance, info.instance},
+		{m.role, info.role},
+		{m.title, info.title},
+	} {
+		if field.re != nil && !field.re.MatchString(field.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, r := range rules {
+		m, err := newMatcher(r.Criteria)
+		if err != nil {
+			return nil, fmt.Errorf("invalid criteria in rule %d: %v", i, err)
+		}
+		compiled[i] = compiledRule{rule: r, matcher: m}
+	}
+	return compiled, nil
+}
+
+// matchRules applies all the rules matching the window, later rules overriding the earlier ones
+func matchRules(rules []compiledRule, info windowInfo) placement {
+	var p placement
+	for _, cr := range rules {
+		if !cr.matcher.match(info) {
+			continue
+		}
+		r := cr.rule
+		if r.Workspace != "" {
+			p.workspace = r.Workspace
+		}
+		if r.Output != "" {
+			p.output = r.Output
+		}
+		if r.Column > 0 {
+			p.column = r.Column
+		}
+		if r.Width > 0 {
+			p.width = r.Width
+		}
+		if r.Height > 0 {
+			p.height = r.Height
+		}
+		if r.Floating != Unset {
+			p.floating = r.Floating
+		}
+		if r.Titlebar != Unset {
+			p.titlebar = r.Titlebar
+		}
+		if r.Fullscreen != Unset {
+			p.fullscreen = r.Fullscreen
+		}
+	}
+	return p
+}
+
+// getWindowInfo reads the properties of the window used for matching. Missing properties are left empty
+func (wm *WM) getWindowInfo(win xproto.Window, typ client.Type) windowInfo {
+	info := windowInfo{typ: typ}
+	info.instance, info.class, _ = wm.xc.GetWindowClass(win)
+	info.role, _ = wm.xc.GetWindowRole(win)
+	info.title, _ = wm.xc.GetWindowTitle(win)
+	return info
+}
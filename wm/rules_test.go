@@ -0,0 +1,71 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestMatchRules(t *testing.T) {
+	rules, err := compileRules([]Rule{
+		{Criteria: Criteria{Class: "^Firefox$"}, Workspace: "2"},
+		{Criteria: Criteria{Class: "^Firefox$", Role: "^Preferences$"}, Floating: On, Width: 800},
+		{Criteria: Criteria{Title: "mpv"}, Fullscreen: On, Titlebar: Off},
+		{Criteria: Criteria{Class: "^Thunderbird$", Type: client.TypeNormal}, Output: "eDP-1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		info windowInfo
+		want placement
+	}{
+		{
+			name: "no match",
+			info: windowInfo{class: "Alacritty", typ: client.TypeNormal},
+			want: placement{},
+		},
+		{
+			name: "single rule",
+			info: windowInfo{class: "Firefox", role: "browser", typ: client.TypeNormal},
+			want: placement{workspace: "2"},
+		},
+		{
+			name: "rules combined",
+			info: windowInfo{class: "Firefox", role: "Preferences", typ: client.TypeNormal},
+			want: placement{workspace: "2", floating: On, width: 800},
+		},
+		{
+			name: "title regexp",
+			info: windowInfo{title: "video.mkv - mpv", typ: client.TypeNormal},
+			want: placement{fullscreen: On, titlebar: Off},
+		},
+		{
+			name: "window type",
+			info: windowInfo{class: "Thunderbird", typ: client.TypeNormal},
+			want: placement{output: "eDP-1"},
+		},
+		{
+			name: "window type mismatch",
+			info: windowInfo{class: "Thunderbird", typ: client.TypeUnknown},
+			want: placement{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchRules(rules, tt.info)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %+v, want = %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRulesInvalid(t *testing.T) {
+	if _, err := compileRules([]Rule{{Criteria: Criteria{Title: "("}}}); err == nil {
+		t.Errorf("expected an error for an invalid regular expression")
+	}
+}
@@ -0,0 +1,112 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+// moveFrameToScratchpad hides the frame in the scratchpad, making it floating
+func (wm *WM) moveFrameToScratchpad(f *frame) error {
+	ws := f.workspace()
+	if ws == nil {
+		return fmt.Errorf("frame not contained within any workspace")
+	}
+	transients := ws.transients(f)
+	if !ws.deleteFrame(f) {
+		return fmt.Errorf("frame not contained within workspace %s", ws.name)
+	}
+	if !f.floating {
+		a := ws.area()
+		f.floatGeom = client.Geom{
+			X: a.X + int16(a.W/4),
+			Y: a.Y + int16(a.H/4),
+			W: a.W / 2,
+			H: a.H / 2,
+		}
+	}
+	f.floating = true
+	f.fullscreen = false
+	f.scratchpad = true
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	if err := f.cli.Unmap(); err != nil {
+		return fmt.Errorf("failed to unmap the frame: %v", err)
+	}
+	wm.scratchpad = append(wm.scratchpad, f)
+	for _, t := range transients {
+		if err := wm.moveFrameToScratchpad(t); err != nil {
+			return fmt.Errorf("failed to move transient frame: %v", err)
+		}
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		return fmt.Errorf("failed to update desktop hints: %v", err)
+	}
+	return wm.removeFocus()
+}
+
+// toggleScratchpad hides the scratchpad frame shown on the current workspace if there is one, otherwise shows
+// the next frame from the scratchpad, centred over the workspace
+func (wm *WM) toggleScratchpad() error {
+	ws := wm.currentOutput().activeWs
+	for _, f := range ws.floating {
+		if f.scratchpad && f.transientFor == nil {
+			return wm.moveFrameToScratchpad(f)
+		}
+	}
+	if len(wm.scratchpad) == 0 {
+		return nil
+	}
+	return wm.showScratchpadFrame(wm.scratchpad[0], ws)
+}
+
+// showScratchpadFrame takes the frame out of the scratchpad, centring it over the workspace
+func (wm *WM) showScratchpadFrame(f *frame, ws *workspace) error {
+	wm.deleteScratchpadFrame(f)
+	a := ws.area()
+	geom := f.floatGeom
+	geom.X = a.X + int16((int(a.W)-int(geom.W))/2)
+	geom.Y = a.Y + int16((int(a.H)-int(geom.H))/2)
+	if err := ws.addFloatingFrame(f, geom); err != nil {
+		return err
+	}
+	// bring along the dialogs of the frame
+	for i := 0; i < len(wm.scratchpad); i++ {
+		if t := wm.scratchpad[i]; t.transientFor == f {
+			wm.scratchpad = append(wm.scratchpad[:i], wm.scratchpad[i+1:]...)
+			i--
+			tg := t.floatGeom
+			tg.X = geom.X + int16((int(geom.W)-int(tg.W))/2)
+			tg.Y = geom.Y + int16((int(geom.H)-int(tg.H))/2)
+			if err := ws.addFloatingFrame(t, tg); err != nil {
+				return err
+			}
+		}
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	if err := wm.raiseFrame(f); err != nil {
+		return err
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		return fmt.Errorf("failed to update desktop hints: %v", err)
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// deleteScratchpadFrame removes the frame from the scratchpad, returning false if it was not there
+func (wm *WM) deleteScratchpadFrame(f *frame) bool {
+	for i, frm := range wm.scratchpad {
+		if frm == f {
+			wm.scratchpad = append(wm.scratchpad[:i], wm.scratchpad[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
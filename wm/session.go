@@ -0,0 +1,188 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// session is a saved layout together with the information needed to launch its applications again
+type session struct {
+	Layout  layoutState     `json:"layout"`
+	Windows []sessionWindow `json:"windows"`
+}
+
+type sessionWindow struct {
+	Window   xproto.Window `json:"window"`
+	PID      int           `json:"pid,omitempty"`
+	Command  []string      `json:"command,omitempty"`
+	Dir      string        `json:"dir,omitempty"`
+	Criteria Criteria      `json:"criteria"`
+}
+
+// sessionPath returns the path of the file of the named session, stored in $XDG_DATA_HOME/marwind/sessions
+func sessionPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, "/\x00") {
+		return "", fmt.Errorf("invalid session name %q", name)
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "marwind", "sessions", name+".json"), nil
+}
+
+// saveSession writes the current layout and the command lines of all the windows to the named session file
+func (wm *WM) saveSession(name string) error {
+	path, err := sessionPath(name)
+	if err != nil {
+		return err
+	}
+	s := session{Layout: *wm.saveLayout()}
+	for _, ws := range wm.workspaces {
+		for _, f := range ws.frames() {
+			s.Windows = append(s.Windows, wm.sessionWindow(f))
+		}
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialise session: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// sessionWindow finds the command line of the window's process and the criteria matching the window
+func (wm *WM) sessionWindow(f *frame) sessionWindow {
+	win := f.cli.Window()
+	sw := sessionWindow{Window: win}
+	info := wm.getWindowInfo(win, f.cli.Type())
+	exact := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "^" + regexp.QuoteMeta(s) + "$"
+	}
+	sw.Criteria = Criteria{
+		Class:    exact(info.class),
+		Instance: exact(info.instance),
+		Role:     exact(info.role),
+		Type:     info.typ,
+	}
+	if info.class == "" && info.instance == "" {
+		sw.Criteria.Title = exact(info.title)
+	}
+
+	pid, err := wm.xc.GetWindowPID(win)
+	if err != nil {
+		return sw
+	}
+	sw.PID = pid
+	if data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil && len(data) > 0 {
+		sw.Command = strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	}
+	if dir, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid)); err == nil {
+		sw.Dir = dir
+	}
+	return sw
+}
+
+// restoreSession reserves the places of the windows saved in the named session and launches their commands.
+// The new windows take their places as they are mapped
+func (wm *WM) restoreSession(name string) error {
+	path, err := sessionPath(name)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("failed to parse session %q: %v", name, err)
+	}
+	windows := make(map[xproto.Window]sessionWindow)
+	for _, sw := range s.Windows {
+		windows[sw.Window] = sw
+	}
+	newPlaceholder := func(ws string, fs frameState) *placeholder {
+		sw, ok := windows[fs.Window]
+		if !ok {
+			return nil
+		}
+		m, err := newMatcher(sw.Criteria)
+		if err != nil {
+			log.Printf("Invalid criteria of window %d in session %q: %v\n", fs.Window, name, err)
+			return nil
+		}
+		return &placeholder{
+			matcher:    m,
+			workspace:  ws,
+			weight:     fs.Height,
+			geom:       fs.Geom,
+			fullscreen: fs.Fullscreen,
+			noTitlebar: fs.NoTitlebar,
+			expires:    time.Now().Add(placeholderTimeout),
+		}
+	}
+	// the frames of the nested containers take the places in the slots nested the same way
+	var addSlots func(ws string, sc *slotColumn, states []frameState)
+	addSlots = func(ws string, sc *slotColumn, states []frameState) {
+		for row, fs := range states {
+			if fs.Split != nil {
+				addSlots(ws, sc.addSplit(row, fs.Split.Horizontal, fs.Height), fs.Split.Frames)
+				continue
+			}
+			if ph := newPlaceholder(ws, fs); ph != nil {
+				ph.slot = sc
+				ph.row = row
+				wm.placeholders = append(wm.placeholders, ph)
+			}
+		}
+	}
+	s.Layout.normalize()
+	for _, wss := range s.Layout.Workspaces {
+		sl := &slotLayout{}
+		for _, cs := range wss.Columns {
+			addSlots(wss.Name, sl.addColumn(cs.Width), cs.Frames)
+		}
+		for _, fs := range wss.Floating {
+			if ph := newPlaceholder(wss.Name, fs); ph != nil {
+				wm.placeholders = append(wm.placeholders, ph)
+			}
+		}
+	}
+
+	// windows sharing a process are launched only once
+	launched := make(map[int]bool)
+	for _, sw := range s.Windows {
+		if len(sw.Command) == 0 || (sw.PID != 0 && launched[sw.PID]) {
+			continue
+		}
+		launched[sw.PID] = true
+		cmd := exec.Command(sw.Command[0], sw.Command[1:]...)
+		cmd.Dir = sw.Dir
+		go func() {
+			if err := cmd.Run(); err != nil {
+				log.Printf("Failed to run command (%s): %v\n", cmd, err)
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package wm
+
+import (
+	"sort"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+// layer is a group of frames stacked together. Frames of a higher layer are always above the frames
+// of the lower ones
+type layer uint8
+
+const (
+	layerDesktop layer = iota
+	layerTiled
+	layerFloating
+	layerAbove
+	layerFullscreen
+	layerNotification
+)
+
+// frameLayer returns the layer of the frame. Transient frames are kept at least in the layer of their parents
+func frameLayer(f *frame) layer {
+	var l layer
+	switch {
+	case f.cli.Type() == client.TypeDesktop:
+		l = layerDesktop
+	case f.cli.Type() == client.TypeNotification:
+		l = layerNotification
+	case f.fullscreen:
+		l = layerFullscreen
+	case f.above || f.cli.Type() == client.TypeDock:
+		l = layerAbove
+	case f.floating:
+		l = layerFloating
+	default:
+		l = layerTiled
+	}
+	if f.transientFor != nil {
+		if pl := frameLayer(f.transientFor); pl > l {
+			l = pl
+		}
+	}
+	return l
+}
+
+// stackingOrder returns all the frames from the bottom to the top of the stack
+func (wm *WM) stackingOrder() []*frame {
+	frames := append([]*frame(nil), wm.stack...)
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frameLayer(frames[i]) < frameLayer(frames[j])
+	})
+	return frames
+}
+
+// raiseFrame puts the frame on top of the other frames of its layer, followed by the frames that are
+// transient for it
+func (wm *WM) raiseFrame(f *frame) error {
+	wm.moveToTop(f)
+	return wm.restack()
+}
+
+func (wm *WM) moveToTop(f *frame) {
+	for i, frm := range wm.stack {
+		if frm == f {
+			wm.stack = append(append(wm.stack[:i], wm.stack[i+1:]...), f)
+			break
+		}
+	}
+	if ws := f.workspace(); ws != nil {
+		for _, t := range ws.transients(f) {
+			wm.moveToTop(t)
+		}
+	}
+}
+
+// restack applies the stacking order to the mapped windows of the frames and publishes it
+// in the _NET_CLIENT_LIST_STACKING property. Nothing is sent unless the order or the set of mapped
+// frames changed since the last call. The errors of the requests are reported by the event loop
+func (wm *WM) restack() error {
+	var mapped, windows []xproto.Window
+	for _, f := range wm.stackingOrder() {
+		if f.cli.Mapped() {
+			win := f.cli.Parent()
+			if win == 0 {
+				win = f.cli.Window()
+			}
+			mapped = append(mapped, win)
+		}
+		// the frames hidden in the scratchpad are not part of the client list
+		if f.workspace() != nil || !f.scratchpad {
+			windows = append(windows, f.cli.Window())
+		}
+	}
+	if !sameWindows(mapped, wm.stacked) {
+		for i := 1; i < len(mapped); i++ {
+			xproto.ConfigureWindow(wm.xc.X(), mapped[i], xproto.ConfigWindowSibling|xproto.ConfigWindowStackMode,
+				[]uint32{uint32(mapped[i-1]), xproto.StackModeAbove})
+		}
+		wm.stacked = mapped
+	}
+	if sameWindows(windows, wm.stackingList) {
+		return nil
+	}
+	wm.stackingList = windows
+	return wm.xc.SetClientListStacking(windows)
+}
+
+func sameWindows(a, b []xproto.Window) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// layoutTemplate describes the columns of a workspace, with placeholders for the windows that are to fill them.
+// Widths and heights are relative to the size of the workspace, e.g.:
+//
+//	{"columns": [
+//		{"width": 0.6, "frames": [{"criteria": {"class": "^Firefox$"}}]},
+//		{"width": 0.4, "frames": [
+//			{"height": 0.7, "criteria": {"class": "^Alacritty$"}},
+//			{"height": 0.3, "criteria": {"class": "^Spotify$"}}
+//		]}
+//	]}
+type layoutTemplate struct {
+	Columns []templateColumn `json:"columns"`
+}
+
+type templateColumn struct {
+	Width  float64         `json:"width,omitempty"`
+	Frames []templateFrame `json:"frames"`
+}
+
+type templateFrame struct {
+	Height     float64  `json:"height,omitempty"`
+	Criteria   Criteria `json:"criteria"`
+	Fullscreen bool     `json:"fullscreen,omitempty"`
+	NoTitlebar bool     `json:"no_titlebar,omitempty"`
+}
+
+// appendLayout loads the layout template from the file, adding its placeholders to the workspace. The windows
+// mapped afterwards fill the first placeholder matching them
+func (wm *WM) appendLayout(ws *workspace, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var t layoutTemplate
+	if err := json.Unmarshal(data, &t); err != nil {
+		return fmt.Errorf("failed to parse layout %q: %v", path, err)
+	}
+	placeholders, err := t.placeholders(ws)
+	if err != nil {
+		return fmt.Errorf("invalid layout %q: %v", path, err)
+	}
+	wm.placeholders = append(wm.placeholders, placeholders...)
+	return nil
+}
+
+// placeholders creates the placeholders of all the frames of the template
+func (t *layoutTemplate) placeholders(ws *workspace) ([]*placeholder, error) {
+	var placeholders []*placeholder
+	sl := &slotLayout{}
+	for _, tc := range t.Columns {
+		sc := sl.addColumn(tc.Width)
+		for row, tf := range tc.Frames {
+			m, err := newMatcher(tf.Criteria)
+			if err != nil {
+				return nil, err
+			}
+			placeholders = append(placeholders, &placeholder{
+				matcher:    m,
+				workspace:  ws.name,
+				slot:       sc,
+				row:        row,
+				weight:     tf.Height,
+				fullscreen: tf.Fullscreen,
+				noTitlebar: tf.NoTitlebar,
+			})
+		}
+	}
+	return placeholders, nil
+}
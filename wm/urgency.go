@@ -0,0 +1,92 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Root window properties that other programs can follow to be notified of the windows that need attention
+const (
+	urgentProp        = "_MARWIND_URGENT"         // names of the workspaces with urgent windows
+	desktopUrgentProp = "_MARWIND_DESKTOP_URGENT" // number of urgent windows on each desktop
+)
+
+// updateUrgency adds the frame to the list of urgent frames if its client needs attention, or removes it from
+// the list otherwise. The focused frame never needs attention
+func (wm *WM) updateUrgency(f *frame) error {
+	if f.cli.Window() == wm.activeWin {
+		return wm.clearUrgency(f)
+	}
+	i := wm.urgentIndex(f)
+	if f.cli.Urgent() && i < 0 {
+		wm.urgent = append(wm.urgent, f)
+	} else if !f.cli.Urgent() && i >= 0 {
+		wm.urgent = append(wm.urgent[:i], wm.urgent[i+1:]...)
+	}
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	return wm.updateUrgentHint()
+}
+
+// clearUrgency removes the frame from the list of urgent frames, resetting its _NET_WM_STATE_DEMANDS_ATTENTION
+// state. The urgency hint is left for the client to reset
+func (wm *WM) clearUrgency(f *frame) error {
+	if i := wm.urgentIndex(f); i >= 0 {
+		wm.urgent = append(wm.urgent[:i], wm.urgent[i+1:]...)
+	} else if !f.cli.DemandsAttention() {
+		return nil
+	}
+	f.cli.SetDemandsAttention(false)
+	if err := wm.updateWindowState(f); err != nil {
+		return err
+	}
+	return wm.updateUrgentHint()
+}
+
+func (wm *WM) urgentIndex(f *frame) int {
+	for i, frm := range wm.urgent {
+		if frm == f {
+			return i
+		}
+	}
+	return -1
+}
+
+// focusUrgent activates the frame that has been waiting for attention the longest
+func (wm *WM) focusUrgent() error {
+	if len(wm.urgent) == 0 {
+		return nil
+	}
+	return wm.activateFrame(wm.urgent[0], xproto.TimeCurrentTime)
+}
+
+// updateUrgentHint publishes the names of the workspaces containing urgent frames, and the number of urgent
+// frames on each desktop
+func (wm *WM) updateUrgentHint() error {
+	var names []byte
+	seen := make(map[*workspace]bool)
+	for _, f := range wm.urgent {
+		if ws := f.workspace(); ws != nil && !seen[ws] {
+			seen[ws] = true
+			names = append(names, ws.name...)
+			names = append(names, 0)
+		}
+	}
+	if err := wm.xc.SetRootProp(urgentProp, names); err != nil {
+		return err
+	}
+	return wm.xc.SetRootCardinals(desktopUrgentProp, wm.urgentCounts(wm.desktops()))
+}
+
+// urgentCounts returns the number of urgent frames on each of the desktops
+func (wm *WM) urgentCounts(desktops []*workspace) []uint32 {
+	counts := make([]uint32, len(desktops))
+	for _, f := range wm.urgent {
+		for i, ws := range desktops {
+			if f.workspace() == ws {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,84 @@
+package wm
+
+// minShare is the smallest part of the workspace that a column, or a frame of a column, can be resized to
+const minShare = 0.1
+
+// Columns and frames are sized with weights, relative to the other columns of the workspace or the other frames
+// of the column. The weights are kept adding up to 1 and converted to pixels only when rendering, so that the
+// proportions survive windows closing and the workspace area changing
+
+// newWeight returns the weight of an item added to the ones with the given weights, taking an equal share
+func newWeight(weights []*float64) float64 {
+	if len(weights) == 0 {
+		return 1
+	}
+	var total float64
+	for _, w := range weights {
+		total += *w
+	}
+	return total / float64(len(weights))
+}
+
+// normalize scales the weights so that they add up to 1, keeping their proportions. If there is nothing
+// to scale, all the items get equal weights
+func normalize(weights []*float64) {
+	var total float64
+	for _, w := range weights {
+		total += *w
+	}
+	for _, w := range weights {
+		if total > 0 {
+			*w /= total
+		} else {
+			*w = 1 / float64(len(weights))
+		}
+	}
+}
+
+// setShare changes the share of the weight at index i, scaling the other weights to fill the rest
+func setShare(weights []*float64, i int, share float64) {
+	if len(weights) < 2 {
+		return
+	}
+	normalize(weights)
+	max := 1 - minShare*float64(len(weights)-1)
+	switch {
+	case share < minShare:
+		share = minShare
+	case share > max:
+		share = max
+	}
+	others := 1 - *weights[i]
+	for j, w := range weights {
+		if j == i {
+			continue
+		}
+		if others > 0 {
+			*w = *w / others * (1 - share)
+		} else {
+			*w = (1 - share) / float64(len(weights)-1)
+		}
+	}
+	*weights[i] = share
+}
+
+// splitSize divides the size in pixels into parts proportional to the weights, adding up exactly to the size
+func splitSize(size uint16, weights []float64) []uint16 {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	sizes := make([]uint16, len(weights))
+	var sum float64
+	var prev uint16
+	for i, w := range weights {
+		sum += w
+		end := size
+		if i < len(weights)-1 && total > 0 {
+			end = uint16(sum/total*float64(size) + 0.5)
+		}
+		sizes[i] = end - prev
+		prev = end
+	}
+	return sizes
+}
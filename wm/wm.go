@@ -3,6 +3,7 @@ package wm
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
@@ -10,34 +11,50 @@ import (
 	"github.com/patrislav/marwind/x11"
 )
 
-const maxWorkspaces = 10
-
 // WM is a struct representing the Window Manager
 type WM struct {
 	xc           *x11.Connection
 	outputs      []*output
+	lastOutput   *output // the output focused or under the pointer last
 	keymap       keysym.Keymap
 	actions      []*action
 	config       Config
-	workspaces   [maxWorkspaces]*workspace
+	workspaces   []*workspace
 	activeWin    xproto.Window
 	windowConfig *client.Config
+	rules        []compiledRule
+	placeholders []*placeholder
+	scratchpad   []*frame
+	stack        []*frame         // all the frames, from the bottom to the top of the stack within their layers
+	urgent       []*frame         // the frames needing attention, from the oldest
+	stacked      []xproto.Window  // the mapped windows in the stacking order last applied by restack
+	stackingList []xproto.Window  // the last published _NET_CLIENT_LIST_STACKING
+	lastUserTime xproto.Timestamp // the time of the last user input, as far as the WM knows
+	restarted    bool
+
+	// showingDesktop is set while the windows of all the workspaces are hidden to show the desktop
+	showingDesktop bool
 }
 
 // New initializes a WM and creates an X11 connection
 func New(config Config) (*WM, error) {
 	wc := &client.Config{
 		BgColor:        config.BorderColor,
+		UrgentBgColor:  config.TitleBarBgColorUrgent,
 		TitlebarHeight: config.TitleBarHeight,
 		FontColor:      config.TitleBarFontColorActive,
 		FontSize:       config.TitleBarFontSize,
 		BorderWidth:    config.BorderWidth,
 	}
+	rules, err := compileRules(config.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile rules: %v", err)
+	}
 	xconn, err := x11.Connect()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WM: %v", err)
 	}
-	wm := &WM{xc: xconn, config: config, windowConfig: wc}
+	wm := &WM{xc: xconn, config: config, windowConfig: wc, rules: rules}
 	return wm, nil
 }
 
@@ -46,7 +63,17 @@ func (wm *WM) Init() error {
 	if err := wm.xc.Init(); err != nil {
 		return fmt.Errorf("failed to init WM: %v", err)
 	}
-	if err := wm.becomeWM(); err != nil {
+	if err := wm.xc.InitRandR(); err != nil {
+		log.Println("WARNING:", err)
+	}
+	layout := wm.loadLayout()
+	err := wm.becomeWM()
+	// after an in-place restart the previous process might not have been disconnected yet
+	for i := 0; layout != nil && err != nil && i < 20; i++ {
+		time.Sleep(50 * time.Millisecond)
+		err = wm.becomeWM()
+	}
+	if err != nil {
 		if _, ok := err.(xproto.AccessError); ok {
 			return fmt.Errorf("could not become WM, possibly another WM is already running")
 		}
@@ -62,28 +89,27 @@ func (wm *WM) Init() error {
 		return fmt.Errorf("failed to grab keys: %v", err)
 	}
 
-	o := newOutput(wm.xc, client.Geom{
-		X: 0, Y: 0,
-		W: wm.xc.Screen().WidthInPixels,
-		H: wm.xc.Screen().HeightInPixels,
-	})
-	for i := 0; i < maxWorkspaces; i++ {
-		wm.workspaces[i] = newWorkspace(uint8(i), workspaceConfig{gap: wm.config.OuterGap})
-	}
-	if err := o.addWorkspace(wm.workspaces[0]); err != nil {
-		return fmt.Errorf("failed to add workspace to output: %v", err)
-	}
-	wm.outputs = append(wm.outputs, o)
+	wm.initOutputs()
+	for _, o := range wm.outputs {
+		if err := o.addWorkspace(wm.newWorkspace(wm.initialWorkspaceName(o))); err != nil {
+			return fmt.Errorf("failed to add workspace to output: %v", err)
+		}
+	}
 
 	if err := wm.xc.SetWMName("Marwind"); err != nil {
 		return fmt.Errorf("failed to set WM name: %v", err)
 	}
-	if err := wm.manageExistingClients(); err != nil {
+	if err := wm.manageExistingClients(layout); err != nil {
 		return fmt.Errorf("failed to manage existing clients: %v", err)
 	}
 	return nil
 }
 
+// Restarted reports whether the WM replaced its previous instance, restoring its layout
+func (wm *WM) Restarted() bool {
+	return wm.restarted
+}
+
 // Close cleans up the WM's resources
 func (wm *WM) Close() {
 	if wm.xc != nil {
@@ -108,6 +134,7 @@ func (wm *WM) becomeWM() error {
 			xproto.EventMaskKeyRelease |
 			xproto.EventMaskButtonPress |
 			xproto.EventMaskButtonRelease |
+			xproto.EventMaskEnterWindow |
 			xproto.EventMaskPropertyChange |
 			xproto.EventMaskFocusChange |
 			xproto.EventMaskStructureNotify |
@@ -139,14 +166,17 @@ func (wm *WM) grabKeys() error {
 
 func (wm *WM) findFrame(predicate func(*frame) bool) *frame {
 	for _, ws := range wm.workspaces {
-		for _, col := range ws.columns {
-			for _, f := range col.frames {
-				if predicate(f) {
-					return f
-				}
+		for _, f := range ws.frames() {
+			if predicate(f) {
+				return f
 			}
 		}
 	}
+	for _, f := range wm.scratchpad {
+		if predicate(f) {
+			return f
+		}
+	}
 	for _, o := range wm.outputs {
 		for area := range o.dockAreas {
 			for _, f := range o.dockAreas[area] {
@@ -155,13 +185,67 @@ func (wm *WM) findFrame(predicate func(*frame) bool) *frame {
 				}
 			}
 		}
+		for _, f := range o.desktopWins {
+			if predicate(f) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func (wm *WM) findWorkspace(predicate func(*workspace) bool) *workspace {
+	for _, ws := range wm.workspaces {
+		if predicate(ws) {
+			return ws
+		}
+	}
+	return nil
+}
+
+func (wm *WM) findOutput(predicate func(*output) bool) *output {
+	for _, o := range wm.outputs {
+		if predicate(o) {
+			return o
+		}
 	}
 	return nil
 }
 
 func (wm *WM) deleteFrame(f *frame) error {
+	for _, ws := range wm.workspaces {
+		for _, t := range ws.transients(f) {
+			t.transientFor = nil
+		}
+	}
+	for _, t := range wm.scratchpad {
+		if t.transientFor == f {
+			t.transientFor = nil
+		}
+	}
+	for i, frm := range wm.stack {
+		if frm == f {
+			wm.stack = append(wm.stack[:i], wm.stack[i+1:]...)
+			break
+		}
+	}
+	if f.userTimeWin != 0 && f.userTimeWin != f.cli.Window() {
+		// the window might already be destroyed along with the client
+		_ = xproto.ChangeWindowAttributesChecked(wm.xc.X(), f.userTimeWin, xproto.CwEventMask, []uint32{0}).Check()
+	}
+	if i := wm.urgentIndex(f); i >= 0 {
+		wm.urgent = append(wm.urgent[:i], wm.urgent[i+1:]...)
+		if err := wm.updateUrgentHint(); err != nil {
+			log.Println("Failed to update urgent workspaces:", err)
+		}
+	}
+	if wm.deleteScratchpadFrame(f) {
+		return nil
+	}
+	ws := f.workspace()
 	for _, o := range wm.outputs {
 		if o.deleteFrame(f) {
+			wm.pruneWorkspace(ws)
 			if err := wm.removeFocus(); err != nil {
 				return err
 			}
@@ -181,26 +265,91 @@ func (wm *WM) handleKeyPressEvent(e xproto.KeyPressEvent) error {
 	return nil
 }
 
+// desktops returns the workspaces of all the outputs in the order in which they are exposed as EWMH desktops
+func (wm *WM) desktops() []*workspace {
+	var desktops []*workspace
+	for _, o := range wm.outputs {
+		desktops = append(desktops, o.workspaces...)
+	}
+	return desktops
+}
+
+// setShowingDesktop hides or shows again the windows of the workspaces shown on all outputs
+func (wm *WM) setShowingDesktop(showing bool) error {
+	if showing == wm.showingDesktop {
+		return nil
+	}
+	wm.showingDesktop = showing
+	for _, o := range wm.outputs {
+		if showing {
+			if err := o.activeWs.hide(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := o.activeWs.show(); err != nil {
+			return err
+		}
+		if err := wm.renderWorkspace(o.activeWs); err != nil {
+			return err
+		}
+	}
+	if showing {
+		if err := wm.removeFocus(); err != nil {
+			return err
+		}
+	}
+	return wm.xc.SetShowingDesktop(showing)
+}
+
+// updateWorkArea publishes the size of the screen and the areas of the desktops not covered by docks
+func (wm *WM) updateWorkArea(desktops []*workspace) error {
+	screen := wm.screenGeom()
+	if err := wm.xc.SetDesktopGeometry(screen.W, screen.H); err != nil {
+		return err
+	}
+	areas := make([]xproto.Rectangle, len(desktops))
+	for i, ws := range desktops {
+		a := ws.output.workspaceArea()
+		areas[i] = xproto.Rectangle{X: a.X, Y: a.Y, Width: a.W, Height: a.H}
+	}
+	return wm.xc.SetWorkArea(areas)
+}
+
+// screenGeom returns the smallest rectangle containing all the outputs
+func (wm *WM) screenGeom() client.Geom {
+	x1, y1, x2, y2 := int(wm.outputs[0].geom.X), int(wm.outputs[0].geom.Y), 0, 0
+	for _, o := range wm.outputs {
+		x1, y1 = minInt(x1, int(o.geom.X)), minInt(y1, int(o.geom.Y))
+		x2, y2 = maxInt(x2, int(o.geom.X)+int(o.geom.W)), maxInt(y2, int(o.geom.Y)+int(o.geom.H))
+	}
+	return client.Geom{X: int16(x1), Y: int16(y1), W: uint16(x2 - x1), H: uint16(y2 - y1)}
+}
+
 // TODO: avoid updating all hints at once
 func (wm *WM) updateDesktopHints() error {
-	out := wm.outputs[0]
-	wsWins := make([][]xproto.Window, len(out.workspaces))
-	names := make([]string, len(out.workspaces))
+	desktops := wm.desktops()
+	wsWins := make([][]xproto.Window, len(desktops))
+	names := make([]string, len(desktops))
 	current := 0
-	for i, ws := range out.workspaces {
-		names[i] = fmt.Sprintf("%d", ws.id+1)
-		for _, col := range ws.columns {
-			for _, f := range col.frames {
-				wsWins[i] = append(wsWins[i], f.cli.Window())
-			}
+	currentWs := wm.currentOutput().activeWs
+	for i, ws := range desktops {
+		names[i] = ws.name
+		for _, f := range ws.frames() {
+			wsWins[i] = append(wsWins[i], f.cli.Window())
 		}
-		if ws == out.activeWs {
+		if ws == currentWs {
 			current = i
+		}
+		if out := ws.output; ws == out.activeWs {
 			for area := range out.dockAreas {
 				for _, f := range out.dockAreas[area] {
 					wsWins[i] = append(wsWins[i], f.cli.Window())
 				}
 			}
+			for _, f := range out.desktopWins {
+				wsWins[i] = append(wsWins[i], f.cli.Window())
+			}
 		}
 	}
 	windows := make([]xproto.Window, 0)
@@ -210,6 +359,12 @@ func (wm *WM) updateDesktopHints() error {
 	if err := wm.xc.SetDesktopHints(names, current, windows); err != nil {
 		return err
 	}
+	if err := wm.updateWorkArea(desktops); err != nil {
+		return err
+	}
+	if err := wm.updateUrgentHint(); err != nil {
+		return err
+	}
 	var err error
 	for i, wins := range wsWins {
 		for _, win := range wins {
@@ -244,25 +399,50 @@ func (wm *WM) handleConfigureRequest(e xproto.ConfigureRequestEvent) error {
 	return nil
 }
 
-func (wm *WM) manageExistingClients() error {
+// manageExistingClients adopts the windows that existed before the WM was started. If a layout was saved
+// by a previous instance, the windows are arranged according to it
+func (wm *WM) manageExistingClients(layout *layoutState) error {
 	tree, err := xproto.QueryTree(wm.xc.X(), wm.xc.GetRootWindow()).Reply()
 	if err != nil {
 		return err
 	}
+	// the desktop names were set by the previous WM, they allow to put the windows back on their workspaces
+	names, _ := wm.xc.GetDesktopNames()
 	for _, win := range tree.Children {
 		attrs, err := xproto.GetWindowAttributes(wm.xc.X(), win).Reply()
-		if err != nil {
+		if err != nil || attrs.OverrideRedirect {
 			continue
 		}
-		if attrs.MapState == xproto.MapStateUnmapped || attrs.OverrideRedirect {
-			continue
+		// unmapped windows are adopted only if they were iconic, i.e. on a hidden workspace
+		if attrs.MapState == xproto.MapStateUnmapped {
+			if state, err := wm.xc.GetWMState(win); err != nil || state != client.IconicState {
+				continue
+			}
 		}
-		if err := wm.manageWindow(win); err != nil {
+		var wsName string
+		if i, err := wm.xc.GetWindowDesktop(win); err == nil && i < len(names) {
+			wsName = names[i]
+		}
+		if err := wm.manageWindow(win, wsName, true); err != nil {
 			log.Println("Failed to manage an existing window:", err)
 		}
 	}
+	if layout != nil {
+		wm.restarted = true
+		if err := wm.xc.DeleteRootProp(layoutProp); err != nil {
+			log.Println("Failed to delete the saved layout:", err)
+		}
+		if err := wm.restoreLayout(layout); err != nil {
+			log.Println("Failed to restore the layout:", err)
+		}
+	}
 	if err := wm.updateDesktopHints(); err != nil {
 		return err
 	}
-	return wm.renderOutput(wm.outputs[0])
+	for _, o := range wm.outputs {
+		if err := wm.renderOutput(o); err != nil {
+			return err
+		}
+	}
+	return nil
 }
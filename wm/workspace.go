@@ -1,30 +1,98 @@
 package wm
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/patrislav/marwind/client"
 )
 
+// defaultScrollWidth is the width of new columns of scrolling workspaces, unless configured otherwise
+const defaultScrollWidth = 0.5
+
 type workspaceConfig struct {
-	gap uint16
+	gap         uint16
+	scrollWidth float64 // width of new columns of scrolling workspaces, as a share of the area
 }
 
 type workspace struct {
-	id      uint8
-	columns []*column
-	output  *output
-	config  workspaceConfig
+	name     string
+	num      int // number the name starts with, or -1 for workspaces with names not starting with a number
+	columns  []*column
+	floating []*frame
+	hidden   []*frame // minimised frames, in the order in which they were hidden
+	// splitAfter is the frame split by the user, next to which the next new frame is placed
+	splitAfter *frame
+
+	// scrolling workspaces keep the widths of the columns as shares of the area, placing them on a strip
+	// scrolled to keep the focused column visible
+	scrolling bool
+	scroll    int // offset of the visible part of the strip from its start, in pixels
+	output    *output
+	config    workspaceConfig
 }
 
-func newWorkspace(id uint8, config workspaceConfig) *workspace {
-	return &workspace{id: id, config: config}
+func newWorkspace(name string, config workspaceConfig) *workspace {
+	ws := &workspace{config: config}
+	ws.setName(name)
+	return ws
 }
 
 func (ws *workspace) setOutput(o *output) {
 	ws.output = o
 }
 
-// addFrame appends the given frame to the last column in the workspace
+// setName changes the name of the workspace, along with the number parsed from it (e.g. 2 for "2:web")
+func (ws *workspace) setName(name string) {
+	ws.name = name
+	ws.num = -1
+	digits := len(name) - len(strings.TrimLeft(name, "0123456789"))
+	if n, err := strconv.Atoi(name[:digits]); err == nil {
+		ws.num = n
+	}
+}
+
+// workspaceLess orders the workspaces by their numbers, placing the ones without numbers at the end
+func workspaceLess(a, b *workspace) bool {
+	switch {
+	case a.num < 0:
+		return false
+	case b.num < 0:
+		return true
+	}
+	return a.num < b.num
+}
+
+// frames returns all the frames of the workspace, tiled ones first
+func (ws *workspace) frames() []*frame {
+	frames := make([]*frame, 0, len(ws.floating)+len(ws.hidden))
+	for _, col := range ws.columns {
+		frames = append(frames, col.frames()...)
+	}
+	frames = append(frames, ws.floating...)
+	return append(frames, ws.hidden...)
+}
+
+// transients returns the floating frames of the workspace that are transient for the given frame
+func (ws *workspace) transients(f *frame) []*frame {
+	var frames []*frame
+	for _, t := range ws.floating {
+		if t.transientFor == f {
+			frames = append(frames, t)
+		}
+	}
+	return frames
+}
+
+// addFrame appends the given frame to the last column in the workspace, or puts it next to the frame
+// split last
 func (ws *workspace) addFrame(f *frame) error {
+	if after := ws.splitAfter; after != nil && after.tiled() && after.workspace() == ws {
+		ws.splitAfter = nil
+		after.col.addFrame(f, after)
+		return ws.mapFrame(f)
+	}
+	ws.splitAfter = nil
 	var col *column
 	if len(ws.columns) < 2 {
 		col = ws.createColumn(false)
@@ -33,134 +101,447 @@ func (ws *workspace) addFrame(f *frame) error {
 		col = ws.columns[len(ws.columns)-1]
 	}
 	col.addFrame(f, nil)
+	return ws.mapFrame(f)
+}
+
+// addFrameToColumn adds the given frame to the column at index i, creating a new column at the end
+// of the workspace if there are not enough of them
+func (ws *workspace) addFrameToColumn(f *frame, i int) error {
+	if i < 0 || i >= len(ws.columns) {
+		ws.createColumn(false).addFrame(f, nil)
+	} else {
+		ws.columns[i].addFrame(f, nil)
+	}
+	return ws.mapFrame(f)
+}
+
+// addFloatingFrame puts the frame above the tiled ones, using the given geometry
+func (ws *workspace) addFloatingFrame(f *frame, geom client.Geom) error {
+	f.ws = ws
+	f.floating = true
+	f.floatGeom = geom
+	ws.floating = append(ws.floating, f)
+	return ws.mapFrame(f)
+}
+
+// addHiddenFrame adds the frame to the hidden list of the workspace, unmapping it
+func (ws *workspace) addHiddenFrame(f *frame) error {
+	f.ws = ws
+	f.col = nil
+	f.hidden = true
+	ws.hidden = append(ws.hidden, f)
+	return f.cli.Unmap()
+}
+
+// mapFrame maps the frame if the workspace is currently visible, otherwise leaves it iconic
+func (ws *workspace) mapFrame(f *frame) error {
 	if ws.output.activeWs == ws {
 		return f.cli.Map()
 	}
-	return nil
+	return f.cli.Unmap()
 }
 
 // deleteFrame deletes the frame from any column that contains it
 func (ws *workspace) deleteFrame(f *frame) bool {
-	if f.col == nil || f.col.ws != ws {
+	if f.hidden {
+		for i, frm := range ws.hidden {
+			if frm == f {
+				ws.hidden = append(ws.hidden[:i], ws.hidden[i+1:]...)
+				f.ws = nil
+				f.hidden = false
+				return true
+			}
+		}
+		return false
+	}
+	if f.floating {
+		for i, frm := range ws.floating {
+			if frm == f {
+				ws.floating = append(ws.floating[:i], ws.floating[i+1:]...)
+				f.ws = nil
+				return true
+			}
+		}
 		return false
 	}
-	col := f.col
-	col.deleteFrame(f)
-	if len(col.frames) == 0 {
-		ws.deleteColumn(col)
+	if f.col == nil || f.col.ws != ws {
+		return false
 	}
+	ws.removeFrame(f)
 	return true
 }
 
-// moveFrame changes the position of a frame within a column or moves it between columns
-func (ws *workspace) moveFrame(f *frame, dir MoveDirection) error {
-	switch dir {
-	case MoveLeft:
-		i := ws.findColumnIndex(func(c *column) bool { return c == f.col })
-		origCol := f.col
-		origCol.deleteFrame(f)
-		if i == 0 {
-			col := ws.createColumn(true)
-			col.addFrame(f, nil)
-		} else {
-			col := ws.columns[i-1]
-			col.addFrame(f, nil)
-		}
-		if len(origCol.frames) == 0 {
-			ws.deleteColumn(origCol)
+// removeFrame takes the tiled frame out of its container. The nested containers left with a single item
+// are replaced by the item, and the empty ones are removed, along with the empty columns. A column left
+// with a single nested container is split into a column for each of its items
+func (ws *workspace) removeFrame(f *frame) {
+	if ws.splitAfter == f {
+		ws.splitAfter = nil
+	}
+	c := f.col
+	c.deleteNode(f)
+	for c.parent != nil {
+		p := c.parent
+		switch len(c.nodes) {
+		case 0:
+			p.deleteNode(c)
+		case 1:
+			p.replaceNode(c, c.nodes[0])
 		}
-	case MoveRight:
-		i := ws.findColumnIndex(func(c *column) bool { return c == f.col })
-		origCol := f.col
-		origCol.deleteFrame(f)
-		if i == len(ws.columns)-1 {
-			col := ws.createColumn(false)
-			col.addFrame(f, nil)
-		} else {
-			col := ws.columns[i+1]
-			col.addFrame(f, nil)
+		c = p
+	}
+	switch len(c.nodes) {
+	case 0:
+		ws.deleteColumn(c)
+	case 1:
+		if sub, ok := c.nodes[0].(*column); ok {
+			ws.spreadColumn(c, sub)
 		}
-		if len(origCol.frames) == 0 {
-			ws.deleteColumn(origCol)
+	}
+}
+
+// spreadColumn replaces the column holding only the nested container with a column for each item
+// of the container, dividing the width of the column between them
+func (ws *workspace) spreadColumn(col, sub *column) {
+	i := ws.findColumnIndex(func(c *column) bool { return c == col })
+	if i < 0 {
+		return
+	}
+	columns := make([]*column, 0, len(ws.columns)+len(sub.nodes)-1)
+	columns = append(columns, ws.columns[:i]...)
+	for _, n := range sub.nodes {
+		weight := col.weight * *n.share()
+		c, ok := n.(*column)
+		if !ok {
+			c = &column{ws: ws}
+			c.insertNode(n, 0)
 		}
-	case MoveUp:
-		col := f.col
-		i := col.findFrameIndex(func(frm *frame) bool { return f == frm })
-		if i > 0 {
-			other := col.frames[i-1]
-			col.frames[i-1] = f
-			col.frames[i] = other
+		c.parent = nil
+		c.weight = weight
+		columns = append(columns, c)
+	}
+	ws.columns = append(columns, ws.columns[i+1:]...)
+	ws.fitColumns()
+}
+
+// splitFrame prepares the place of the tiled frame to be shared with the next new frame of the workspace,
+// side by side if horizontal or stacked otherwise. The frame is wrapped in a new nested container if its
+// container places the items the other way
+func (ws *workspace) splitFrame(f *frame, horizontal bool) {
+	if !f.tiled() {
+		return
+	}
+	c := f.col
+	switch {
+	case c.horizontal == horizontal:
+	case len(c.nodes) == 1 && c.parent != nil:
+		c.horizontal = horizontal
+		c.parent.absorb(c)
+	default:
+		sub := &column{ws: ws, horizontal: horizontal}
+		c.replaceNode(f, sub)
+		sub.insertNode(f, 0)
+	}
+	ws.splitAfter = f
+}
+
+// moveFrame swaps the frame, or the nested container holding it, with its neighbour in the nearest container
+// placing the items in the direction. Past the edge of the containers, the frame is moved between the columns
+func (ws *workspace) moveFrame(f *frame, dir MoveDirection) error {
+	if !f.tiled() {
+		return nil
+	}
+	horizontal := dir == MoveLeft || dir == MoveRight
+	step := 1
+	if dir == MoveLeft || dir == MoveUp {
+		step = -1
+	}
+	var n node = f
+	for c := f.col; c != nil; n, c = c, c.parent {
+		if c.horizontal != horizontal {
+			continue
 		}
-	case MoveDown:
-		col := f.col
-		i := col.findFrameIndex(func(frm *frame) bool { return f == frm })
-		if i < len(col.frames)-1 {
-			other := col.frames[i+1]
-			col.frames[i+1] = f
-			col.frames[i] = other
+		i := c.index(n)
+		if j := i + step; j >= 0 && j < len(c.nodes) {
+			c.nodes[i], c.nodes[j] = c.nodes[j], c.nodes[i]
+			return nil
 		}
 	}
+	if !horizontal {
+		return nil
+	}
+	col := f.topColumn()
+	i := ws.findColumnIndex(func(c *column) bool { return c == col }) + step
+	var target *column
+	switch {
+	case i < 0:
+		target = ws.createColumn(true)
+	case i >= len(ws.columns):
+		target = ws.createColumn(false)
+	default:
+		target = ws.columns[i]
+	}
+	ws.removeFrame(f)
+	target.addFrame(f, nil)
 	return nil
 }
 
-// resizeFrame changes the size of the frame by the given percent
-func (ws *workspace) resizeFrame(f *frame, dir ResizeDirection, pct int) error {
-	switch dir {
-	case ResizeHoriz:
-		if len(ws.columns) < 2 {
-			return nil
+// moveColumn swaps the column with its neighbour in the given direction, keeping the widths of both
+func (ws *workspace) moveColumn(col *column, dir MoveDirection) {
+	i := ws.findColumnIndex(func(c *column) bool { return c == col })
+	j := i + 1
+	if dir == MoveLeft {
+		j = i - 1
+	}
+	if i < 0 || j < 0 || j >= len(ws.columns) || (dir != MoveLeft && dir != MoveRight) {
+		return
+	}
+	ws.columns[i], ws.columns[j] = ws.columns[j], ws.columns[i]
+}
+
+// moveFrameToNewColumn takes the frame out of its column into a new column inserted on the given side of it.
+// A frame alone in its column is moved past the neighbouring column instead
+func (ws *workspace) moveFrameToNewColumn(f *frame, dir MoveDirection) {
+	if !f.tiled() || (dir != MoveLeft && dir != MoveRight) {
+		return
+	}
+	col := f.topColumn()
+	if len(col.frames()) == 1 {
+		ws.moveColumn(col, dir)
+		return
+	}
+	i := ws.findColumnIndex(func(c *column) bool { return c == col })
+	if dir == MoveRight {
+		i++
+	}
+	ws.removeFrame(f)
+	ws.insertColumn(i).addFrame(f, nil)
+}
+
+// swapFrames exchanges the places of two tiled frames, each taking the height of the other's place
+func (ws *workspace) swapFrames(a, b *frame) {
+	if a == b || !a.tiled() || !b.tiled() {
+		return
+	}
+	ca, cb := a.col, b.col
+	i, j := ca.index(a), cb.index(b)
+	ca.nodes[i], cb.nodes[j] = b, a
+	a.col, b.col = cb, ca
+	a.weight, b.weight = b.weight, a.weight
+}
+
+// rect is a part of the workspace area, in fractions of its size
+type rect struct {
+	x, y, w, h float64
+}
+
+// frameRects returns the places of the tiled frames in the workspace area
+func (ws *workspace) frameRects() map[*frame]rect {
+	rects := make(map[*frame]rect)
+	var x float64
+	for _, col := range ws.columns {
+		col.frameRects(rect{x: x, w: col.weight, h: 1}, rects)
+		x += col.weight
+	}
+	return rects
+}
+
+func (c *column) frameRects(r rect, rects map[*frame]rect) {
+	var pos float64
+	for _, n := range c.nodes {
+		share := *n.share()
+		nr := rect{x: r.x, y: r.y + pos*r.h, w: r.w, h: share * r.h}
+		if c.horizontal {
+			nr = rect{x: r.x + pos*r.w, y: r.y, w: share * r.w, h: r.h}
 		}
-		min := uint16(float32(ws.area().W) * 0.1)
-		dwFull := int(float32(ws.area().W) * (float32(pct) / 100))
-		if uint16(int(f.col.width)+dwFull) < min {
-			return nil
+		pos += share
+		switch n := n.(type) {
+		case *frame:
+			rects[n] = nr
+		case *column:
+			n.frameRects(nr, rects)
 		}
-		dwPart := dwFull/len(ws.columns) - 1
-		dwFinal := 0
-		for _, col := range ws.columns {
-			if col != f.col {
-				next := uint16(int(col.width) - dwPart)
-				if next >= min {
-					col.width = next
-					dwFinal += dwPart
-				}
-			}
+	}
+}
+
+// frameInDirection returns the closest tiled frame in the given direction, lying across the centre of the frame
+func (ws *workspace) frameInDirection(f *frame, dir MoveDirection) *frame {
+	const eps = 1e-9
+	rects := ws.frameRects()
+	r, ok := rects[f]
+	if !ok {
+		return nil
+	}
+	cx, cy := r.x+r.w/2, r.y+r.h/2
+	var closest *frame
+	var closestDist float64
+	for other, o := range rects {
+		across := o.y <= cy && cy < o.y+o.h
+		if dir == MoveUp || dir == MoveDown {
+			across = o.x <= cx && cx < o.x+o.w
 		}
-		f.col.width = uint16(int(f.col.width) + dwFinal)
-	case ResizeVert:
-		col := f.col
-		if len(col.frames) < 2 {
-			return nil
+		var dist float64
+		switch dir {
+		case MoveLeft:
+			dist = r.x - (o.x + o.w)
+		case MoveRight:
+			dist = o.x - (r.x + r.w)
+		case MoveUp:
+			dist = r.y - (o.y + o.h)
+		case MoveDown:
+			dist = o.y - (r.y + r.h)
 		}
-		min := uint16(float32(ws.area().H) * 0.1)
-		dhFull := int(float32(ws.area().H) * (float32(pct) / 100))
-		if uint16(int(f.height)+dhFull) < min {
-			return nil
+		if other == f || !across || dist < -eps {
+			continue
 		}
-		dhPart := dhFull/len(col.frames) - 1
-		dhFinal := 0
-		for _, other := range col.frames {
-			if f != other {
-				next := uint16(int(other.height) - dhPart)
-				if next >= min {
-					other.height = next
-					dhFinal += dhPart
-				}
-			}
+		if closest == nil || dist < closestDist {
+			closest, closestDist = other, dist
+		}
+	}
+	return closest
+}
+
+// resizeFrame changes the size of the frame, or of the nested container holding it, by the given percent
+// of the nearest container placing the items in the direction
+func (ws *workspace) resizeFrame(f *frame, dir ResizeDirection, pct int) error {
+	if !f.tiled() {
+		return nil
+	}
+	horizontal := dir == ResizeHoriz
+	var n node = f
+	for c := f.col; c != nil; n, c = c, c.parent {
+		if c.horizontal == horizontal {
+			setShare(c.weights(), c.index(n), *n.share()+float64(pct)/100)
+			return nil
 		}
-		f.height = uint16(int(f.height) + dhFinal)
+	}
+	if horizontal {
+		i := ws.findColumnIndex(func(c *column) bool { return c == n })
+		ws.setColumnShare(i, *n.share()+float64(pct)/100)
 	}
 	return nil
 }
 
+// setFrameHeight changes the height of the frame, or of the nested container holding it within the nearest
+// container stacking the items vertically, to the given number of pixels of the current workspace area
+func (ws *workspace) setFrameHeight(f *frame, height uint16) {
+	h := ws.area().H
+	if !f.tiled() || h == 0 {
+		return
+	}
+	var n node = f
+	for c := f.col; c != nil; n, c = c, c.parent {
+		if !c.horizontal {
+			setShare(c.weights(), c.index(n), float64(height)/float64(h))
+			return
+		}
+	}
+}
+
+// setColumnWidth changes the width of the column to the given number of pixels of the current workspace area,
+// scaling the other columns to fill the rest of the area
+func (ws *workspace) setColumnWidth(col *column, width uint16) {
+	if w := ws.area().W; w > 0 {
+		ws.setColumnWeight(col, float64(width)/float64(w))
+	}
+}
+
+// setColumnWeight changes the share of the workspace taken by the column, scaling the other columns to fill the rest
+func (ws *workspace) setColumnWeight(col *column, share float64) {
+	if i := ws.findColumnIndex(func(c *column) bool { return c == col }); i >= 0 {
+		ws.setColumnShare(i, share)
+	}
+}
+
+// setColumnShare changes the share of the workspace taken by the column at index i. The other columns are scaled
+// to fill the rest of the area, unless the workspace is scrolling
+func (ws *workspace) setColumnShare(i int, share float64) {
+	if !ws.scrolling {
+		setShare(ws.weights(), i, share)
+		return
+	}
+	switch {
+	case share < minShare:
+		share = minShare
+	case share > 1:
+		share = 1
+	}
+	ws.columns[i].weight = share
+}
+
+// fitColumns scales the weights of the columns proportionally, so that they fill the entire workspace area.
+// The columns of scrolling workspaces keep their widths
+func (ws *workspace) fitColumns() {
+	if !ws.scrolling {
+		normalize(ws.weights())
+	}
+}
+
+// setScrolling switches the workspace between fitting the columns in its area and scrolling through them
+func (ws *workspace) setScrolling(scrolling bool) {
+	if scrolling == ws.scrolling {
+		return
+	}
+	ws.scrolling = scrolling
+	ws.scroll = 0
+	ws.fitColumns()
+}
+
+// columnWidth returns the width of the column of a scrolling workspace, in pixels
+func (ws *workspace) columnWidth(col *column) uint16 {
+	return uint16(col.weight*float64(ws.area().W) + 0.5)
+}
+
+// stripWidth returns the width of all the columns of a scrolling workspace, in pixels
+func (ws *workspace) stripWidth() int {
+	var width int
+	for _, col := range ws.columns {
+		width += int(ws.columnWidth(col))
+	}
+	return width
+}
+
+// scrollTo changes the scroll offset of the workspace, so that the column is entirely visible if it fits
+// in the area. It returns whether the offset changed
+func (ws *workspace) scrollTo(col *column) bool {
+	var start int
+	for _, c := range ws.columns {
+		if c == col {
+			break
+		}
+		start += int(ws.columnWidth(c))
+	}
+	end := start + int(ws.columnWidth(col))
+	scroll := ws.scroll
+	if end > scroll+int(ws.area().W) {
+		scroll = end - int(ws.area().W)
+	}
+	if start < scroll {
+		scroll = start
+	}
+	changed := scroll != ws.scroll
+	ws.scroll = scroll
+	return changed
+}
+
+// weights returns the pointers to the weights of the columns, in order
+func (ws *workspace) weights() []*float64 {
+	weights := make([]*float64, len(ws.columns))
+	for i, col := range ws.columns {
+		weights[i] = &col.weight
+	}
+	return weights
+}
+
 // show maps all the frames of the workspace
 func (ws *workspace) show() error {
 	var err error
-	for _, col := range ws.columns {
-		for _, f := range col.frames {
-			if e := f.cli.Map(); e != nil {
-				err = e
-			}
+	for _, f := range ws.frames() {
+		if f.hidden {
+			continue
+		}
+		if e := f.cli.Map(); e != nil {
+			err = e
 		}
 	}
 	return err
@@ -169,11 +550,9 @@ func (ws *workspace) show() error {
 // hide unmaps all the frames of the workspace
 func (ws *workspace) hide() error {
 	var err error
-	for _, col := range ws.columns {
-		for _, f := range col.frames {
-			if e := f.cli.Unmap(); e != nil {
-				err = e
-			}
+	for _, f := range ws.frames() {
+		if e := f.cli.Unmap(); e != nil {
+			err = e
 		}
 	}
 	return err
@@ -182,43 +561,36 @@ func (ws *workspace) hide() error {
 // createColumn creates a new empty column either at the start (if the start argument is true)
 // or the end of the workspace area.
 func (ws *workspace) createColumn(start bool) *column {
-	wsWidth := ws.area().W
-	origLen := len(ws.columns)
-	col := &column{ws: ws, width: ws.area().W / uint16(origLen+1)}
-	if origLen > 0 {
-		col.width = wsWidth / uint16(origLen+1)
-		remWidth := float32(wsWidth - col.width)
-		leftWidth := uint16(remWidth)
-		for _, c := range ws.columns {
-			c.width = uint16((float32(c.width) / float32(wsWidth)) * remWidth)
-			leftWidth -= c.width
-		}
-		if leftWidth != 0 {
-			col.width += leftWidth
-		}
-	} else {
-		col.width = wsWidth
-	}
 	if start {
-		ws.columns = append([]*column{col}, ws.columns...)
-	} else {
-		ws.columns = append(ws.columns, col)
+		return ws.insertColumn(0)
 	}
+	return ws.insertColumn(len(ws.columns))
+}
+
+// insertColumn creates a new empty column at the given index, shrinking the other columns to make space for it
+func (ws *workspace) insertColumn(i int) *column {
+	col := &column{ws: ws, weight: newWeight(ws.weights())}
+	if ws.scrolling {
+		col.weight = ws.config.scrollWidth
+		if col.weight <= 0 {
+			col.weight = defaultScrollWidth
+		}
+	}
+	ws.columns = append(ws.columns, nil)
+	copy(ws.columns[i+1:], ws.columns[i:])
+	ws.columns[i] = col
+	ws.fitColumns()
 	return col
 }
 
+// deleteColumn removes the column, scaling the other columns to take its space
 func (ws *workspace) deleteColumn(col *column) {
 	i := ws.findColumnIndex(func(c *column) bool { return c == col })
 	if i < 0 {
 		return
 	}
-	wsWidth := ws.area().W
-	// TODO: assign the widths proportional to the original width/totalWidth ratio
-	// origLen = len(ws.columns)
 	ws.columns = append(ws.columns[:i], ws.columns[i+1:]...)
-	for _, c := range ws.columns {
-		c.width = wsWidth / uint16(len(ws.columns))
-	}
+	ws.fitColumns()
 }
 
 func (ws *workspace) findColumnIndex(predicate func(*column) bool) int {
@@ -230,12 +602,6 @@ func (ws *workspace) findColumnIndex(predicate func(*column) bool) int {
 	return -1
 }
 
-func (ws *workspace) updateTiling() {
-	for _, col := range ws.columns {
-		col.updateTiling()
-	}
-}
-
 func (ws *workspace) fullArea() client.Geom { return ws.output.workspaceArea() }
 
 func (ws *workspace) area() client.Geom {
@@ -251,7 +617,7 @@ func (ws *workspace) area() client.Geom {
 // singleFrame returns a single frame if there's only one in the workspace, nil otherwise
 func (ws *workspace) singleFrame() *frame {
 	if ws.countAllFrames() == 1 {
-		return ws.columns[0].frames[0]
+		return ws.columns[0].frames()[0]
 	}
 	return nil
 }
@@ -259,7 +625,7 @@ func (ws *workspace) singleFrame() *frame {
 func (ws *workspace) countAllFrames() int {
 	count := 0
 	for _, col := range ws.columns {
-		count += len(col.frames)
+		count += len(col.frames())
 	}
 	return count
 }
@@ -0,0 +1,173 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestWorkspaceOrder(t *testing.T) {
+	o := newOutput(nil, client.Geom{W: 1000, H: 800})
+	for _, name := range []string{"mail", "10", "2:web", "chat", "1"} {
+		if err := o.addWorkspace(newWorkspace(name, workspaceConfig{})); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := []string{"1", "2:web", "10", "mail", "chat"}
+	for i, ws := range o.workspaces {
+		if ws.name != want[i] {
+			t.Errorf("got workspace %q at position %d, want %q", ws.name, i, want[i])
+		}
+	}
+}
+
+func TestMoveFrameToNewColumn(t *testing.T) {
+	ws := newTestWorkspace()
+	a, b, c := &frame{}, &frame{}, &frame{}
+	left := ws.createColumn(false)
+	left.addFrame(a, nil)
+	left.addFrame(b, nil)
+	ws.createColumn(false).addFrame(c, nil)
+
+	ws.moveFrameToNewColumn(b, MoveRight)
+	if len(ws.columns) != 3 || ws.columns[1].frames()[0] != b || ws.columns[2].frames()[0] != c {
+		t.Fatalf("frame not inserted as a new column between the others")
+	}
+	if got := ws.frameInDirection(a, MoveRight); got != b {
+		t.Errorf("got a wrong frame to the right")
+	}
+
+	ws.swapFrames(a, c)
+	if ws.columns[0].frames()[0] != c || ws.columns[2].frames()[0] != a || a.col != ws.columns[2] {
+		t.Errorf("frames not swapped")
+	}
+
+	ws.moveColumn(a.col, MoveLeft)
+	if ws.columns[1].frames()[0] != a || ws.columns[2].frames()[0] != b {
+		t.Errorf("column not moved to the left")
+	}
+}
+
+func TestDeleteColumnKeepsProportions(t *testing.T) {
+	ws := newTestWorkspace()
+	a, b, c := ws.createColumn(false), ws.createColumn(false), ws.createColumn(false)
+	ws.setColumnWeight(a, 0.5)
+	ws.setColumnWeight(b, 0.3)
+	ratio := a.weight / b.weight
+	ws.deleteColumn(c)
+	if r := a.weight / b.weight; r < ratio-1e-9 || r > ratio+1e-9 {
+		t.Errorf("got the ratio of the weights %v, want %v", r, ratio)
+	}
+	if sum := a.weight + b.weight; sum < 1-1e-9 || sum > 1+1e-9 {
+		t.Errorf("got the weights adding up to %v, want 1", sum)
+	}
+}
+
+func TestSplitSize(t *testing.T) {
+	sizes := splitSize(1000, []float64{1, 1, 1})
+	want := []uint16{333, 334, 333}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Errorf("got sizes %v, want %v", sizes, want)
+			break
+		}
+	}
+}
+
+func TestSplitFrame(t *testing.T) {
+	ws := newTestWorkspace()
+	a, b, c := &frame{}, &frame{}, &frame{}
+	col := ws.createColumn(false)
+	col.addFrame(a, nil)
+	col.addFrame(b, nil)
+
+	ws.splitFrame(b, true)
+	if ws.splitAfter != b {
+		t.Fatalf("the split frame not remembered")
+	}
+	b.col.addFrame(c, b)
+	if b.col == col || c.col != b.col || !b.col.horizontal || b.col.parent != col {
+		t.Fatalf("frames not placed side by side in a nested container")
+	}
+	if got := ws.frameInDirection(c, MoveUp); got != a {
+		t.Errorf("got a wrong frame above the nested container")
+	}
+	if got := ws.frameInDirection(b, MoveRight); got != c {
+		t.Errorf("got a wrong frame to the right in the nested container")
+	}
+
+	ws.removeFrame(c)
+	if b.col != col || len(col.nodes) != 2 || col.nodes[1] != b {
+		t.Errorf("the nested container with a single frame not flattened")
+	}
+	if sum := a.weight + b.weight; sum < 1-1e-9 || sum > 1+1e-9 {
+		t.Errorf("got the weights adding up to %v, want 1", sum)
+	}
+}
+
+func TestRemoveFrameSpreadsColumn(t *testing.T) {
+	ws := newTestWorkspace()
+	a, b, c, d := &frame{}, &frame{}, &frame{}, &frame{}
+	ws.createColumn(false).addFrame(a, nil)
+	col := ws.createColumn(false)
+	col.addFrame(b, nil)
+	col.addFrame(c, nil)
+	ws.splitFrame(c, true)
+	c.col.addFrame(d, c)
+
+	ws.removeFrame(b)
+	if len(ws.columns) != 3 || ws.columns[1] != c.col || ws.columns[2] != d.col || c.col.parent != nil {
+		t.Fatalf("the column holding a single nested container not split into columns")
+	}
+	if c.weight != 1 || c.col.weight != 0.25 || d.col.weight != 0.25 {
+		t.Errorf("got the weights %v, %v, want 0.25 each", c.col.weight, d.col.weight)
+	}
+}
+
+func TestScrollingColumns(t *testing.T) {
+	ws := newTestWorkspace()
+	ws.config.scrollWidth = 0.6
+	ws.setScrolling(true)
+	a, b, c := ws.createColumn(false), ws.createColumn(false), ws.createColumn(false)
+	for _, col := range []*column{a, b, c} {
+		if col.weight != 0.6 {
+			t.Fatalf("got a column weight %v, want 0.6", col.weight)
+		}
+	}
+	if w := ws.stripWidth(); w != 1800 {
+		t.Errorf("got the strip width %d, want 1800", w)
+	}
+
+	if !ws.scrollTo(c) || ws.scroll != 800 {
+		t.Errorf("got the scroll offset %d, want 800", ws.scroll)
+	}
+	if ws.scrollTo(c) {
+		t.Errorf("scrolled to an already visible column")
+	}
+	if !ws.scrollTo(b) || ws.scroll != 600 {
+		t.Errorf("got the scroll offset %d, want 600", ws.scroll)
+	}
+
+	ws.setScrolling(false)
+	if sum := a.weight + b.weight + c.weight; sum < 1-1e-9 || sum > 1+1e-9 {
+		t.Errorf("got the weights adding up to %v, want 1", sum)
+	}
+}
+
+func TestSetFrameHeightNested(t *testing.T) {
+	ws := newTestWorkspace()
+	a, b, c := &frame{}, &frame{}, &frame{}
+	col := ws.createColumn(false)
+	col.addFrame(a, nil)
+	col.addFrame(b, nil)
+	ws.splitFrame(b, true)
+	b.col.addFrame(c, b)
+
+	ws.setFrameHeight(c, 200)
+	if b.weight != c.weight {
+		t.Errorf("the height changed the widths in the nested container: %v, %v", b.weight, c.weight)
+	}
+	if h := b.col.weight; h < 0.25-1e-9 || h > 0.25+1e-9 {
+		t.Errorf("got the nested container weight %v, want 0.25", h)
+	}
+}
@@ -14,6 +14,9 @@ type Connection struct {
 	util   *xgbutil.XUtil
 	screen xproto.ScreenInfo
 	atoms  map[string]xproto.Atom
+
+	// checkWin is the child window of the root referenced by _NET_SUPPORTING_WM_CHECK
+	checkWin xproto.Window
 }
 
 func Connect() (*Connection, error) {
@@ -26,22 +29,20 @@ func Connect() (*Connection, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create XUtil connection: %w", err)
 	}
-	return &Connection{conn: xconn, util: xutil, atoms: atoms}, nil
+	conninfo := xproto.Setup(xconn)
+	if conninfo == nil {
+		return nil, errors.New("could not parse X connection info")
+	}
+	if len(conninfo.Roots) != 1 {
+		return nil, errors.New("wrong number of roots, possibly xinerama did not initialize properly")
+	}
+	return &Connection{conn: xconn, util: xutil, atoms: atoms, screen: conninfo.Roots[0]}, nil
 }
 
 func (xc *Connection) X() *xgb.Conn              { return xc.conn }
 func (xc *Connection) Screen() xproto.ScreenInfo { return xc.screen }
 
 func (xc *Connection) Init() error {
-	conninfo := xproto.Setup(xc.conn)
-	if conninfo == nil {
-		return errors.New("could not parse X connection info")
-	}
-	if len(conninfo.Roots) != 1 {
-		return errors.New("wrong number of roots, possibly xinerama did not initialize properly")
-	}
-	xc.screen = conninfo.Roots[0]
-
 	err := xc.setHints()
 	if err != nil {
 		return err
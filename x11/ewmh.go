@@ -2,47 +2,87 @@ package x11
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/BurntSushi/xgb/xproto"
 )
 
-// Struts represents the values of the _NET_WM_STRUT/_NET_WM_STRUT_PARTIAL properties.
-// The extended _NET_WM_STRUT_PARTIAL values are ignored - the WM will fill the entire width of the screen instead.
+// Struts represents the values of the _NET_WM_STRUT/_NET_WM_STRUT_PARTIAL properties: the widths of the areas
+// reserved at the edges of the screen, and the ranges along the edges which these areas cover
 type Struts struct {
 	Left, Right, Top, Bottom uint32
+
+	LeftStartY, LeftEndY     uint32
+	RightStartY, RightEndY   uint32
+	TopStartX, TopEndX       uint32
+	BottomStartX, BottomEndX uint32
 }
 
-// GetWindowStruts returns the values of the window's _NET_WM_STRUT(_PARTIAL) property
-func (xc *Connection) GetWindowStruts(win xproto.Window) (*Struts, error) {
-	// TODO: support _NET_WM_STRUT_PARTIAL as well
+// GetWindowStruts returns the values of the window's _NET_WM_STRUT_PARTIAL property, falling back to
+// _NET_WM_STRUT, in which case the reserved areas cover the entire edges of the screen of the given size
+func (xc *Connection) GetWindowStruts(win xproto.Window, width, height uint16) (*Struts, error) {
+	if values, err := xc.getProps32(win, "_NET_WM_STRUT_PARTIAL"); err == nil && len(values) >= 12 {
+		return &Struts{
+			Left:         values[0],
+			Right:        values[1],
+			Top:          values[2],
+			Bottom:       values[3],
+			LeftStartY:   values[4],
+			LeftEndY:     values[5],
+			RightStartY:  values[6],
+			RightEndY:    values[7],
+			TopStartX:    values[8],
+			TopEndX:      values[9],
+			BottomStartX: values[10],
+			BottomEndX:   values[11],
+		}, nil
+	}
 	propName := "_NET_WM_STRUT"
-	atom := xc.Atom(propName)
-	prop, err := xproto.GetProperty(xc.conn, false, win, atom, xproto.AtomCardinal, 2, 32).Reply()
+	values, err := xc.getProps32(win, propName)
 	if err != nil {
 		return nil, err
 	}
-	if prop == nil {
-		return nil, fmt.Errorf("xproto.GetProperty returned a nil reply")
-	}
-	values := make([]uint32, len(prop.Value)/4)
-	for v := prop.Value; len(v) >= 4; v = v[4:] {
-		values = append(values, uint32(v[0])|uint32(v[1])<<8|uint32(v[2])<<16|uint32(v[3])<<24)
-	}
 	if len(values) < 4 {
 		return nil, fmt.Errorf("not enough values returned by property %s", propName)
 	}
+	w, h := uint32(width), uint32(height)
 	return &Struts{
-		Left:   values[0],
-		Right:  values[1],
-		Top:    values[2],
-		Bottom: values[3],
+		Left:       values[0],
+		Right:      values[1],
+		Top:        values[2],
+		Bottom:     values[3],
+		LeftEndY:   h - 1,
+		RightEndY:  h - 1,
+		TopEndX:    w - 1,
+		BottomEndX: w - 1,
 	}, nil
 }
 
+// SetWMName publishes the name of the WM on the _NET_SUPPORTING_WM_CHECK window, creating it if needed
 func (xc *Connection) SetWMName(name string) error {
+	if xc.checkWin == 0 {
+		win, err := xproto.NewWindowId(xc.conn)
+		if err != nil {
+			return err
+		}
+		err = xproto.CreateWindowChecked(xc.conn, 0, win, xc.screen.Root, -1, -1, 1, 1, 0,
+			xproto.WindowClassInputOnly, xc.screen.RootVisual, 0, nil).Check()
+		if err != nil {
+			return fmt.Errorf("failed to create the check window: %v", err)
+		}
+		xc.checkWin = win
+	}
+	for _, w := range []xproto.Window{xc.screen.Root, xc.checkWin} {
+		if err := xc.changeProp32(w, "_NET_SUPPORTING_WM_CHECK", xproto.AtomWindow, uint32(xc.checkWin)); err != nil {
+			return err
+		}
+	}
 	buf := make([]byte, 0)
 	buf = append(buf, name...)
 	buf = append(buf, 0)
+	if err := xc.changeProp(xc.checkWin, 8, "_NET_WM_NAME", xc.Atom("UTF8_STRING"), buf); err != nil {
+		return err
+	}
 	return xc.changeProp(xc.screen.Root, 8, "_NET_WM_NAME", xproto.AtomString, buf)
 }
 
@@ -74,6 +114,32 @@ func (xc *Connection) SetDesktopViewport(num int) error {
 	return xc.changeProp32(xc.screen.Root, "_NET_DESKTOP_VIEWPORT", xproto.AtomCardinal, vals...)
 }
 
+func (xc *Connection) SetDesktopGeometry(width, height uint16) error {
+	return xc.changeProp32(xc.screen.Root, "_NET_DESKTOP_GEOMETRY", xproto.AtomCardinal, uint32(width), uint32(height))
+}
+
+// SetWorkArea sets the _NET_WORKAREA property to the areas of the desktops not reserved by docks
+func (xc *Connection) SetWorkArea(areas []xproto.Rectangle) error {
+	vals := make([]uint32, 0, len(areas)*4)
+	for _, a := range areas {
+		vals = append(vals, uint32(a.X), uint32(a.Y), uint32(a.Width), uint32(a.Height))
+	}
+	return xc.changeProp32(xc.screen.Root, "_NET_WORKAREA", xproto.AtomCardinal, vals...)
+}
+
+// SetFrameExtents sets the window's _NET_FRAME_EXTENTS property to the size of its decorations
+func (xc *Connection) SetFrameExtents(win xproto.Window, d Dimensions) error {
+	return xc.changeProp32(win, "_NET_FRAME_EXTENTS", xproto.AtomCardinal, d.Left, d.Right, d.Top, d.Bottom)
+}
+
+func (xc *Connection) SetShowingDesktop(showing bool) error {
+	var val uint32
+	if showing {
+		val = 1
+	}
+	return xc.changeProp32(xc.screen.Root, "_NET_SHOWING_DESKTOP", xproto.AtomCardinal, val)
+}
+
 func (xc *Connection) SetDesktopNames(names []string) error {
 	buf := make([]byte, 0)
 	for _, name := range names {
@@ -83,6 +149,15 @@ func (xc *Connection) SetDesktopNames(names []string) error {
 	return xc.changeProp(xc.screen.Root, 8, "_NET_DESKTOP_NAMES", xc.Atom("UTF8_STRING"), buf)
 }
 
+// GetDesktopNames returns the names stored in the root window's _NET_DESKTOP_NAMES property
+func (xc *Connection) GetDesktopNames() ([]string, error) {
+	reply, err := xc.getProp(xc.screen.Root, "_NET_DESKTOP_NAMES")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(reply.Value), "\x00"), "\x00"), nil
+}
+
 func (xc *Connection) SetClientList(windows []xproto.Window) error {
 	vals := make([]uint32, len(windows))
 	for i, win := range windows {
@@ -91,6 +166,28 @@ func (xc *Connection) SetClientList(windows []xproto.Window) error {
 	return xc.changeProp32(xc.screen.Root, "_NET_CLIENT_LIST", xproto.AtomWindow, vals...)
 }
 
+func (xc *Connection) SetClientListStacking(windows []xproto.Window) error {
+	vals := make([]uint32, len(windows))
+	for i, win := range windows {
+		vals[i] = uint32(win)
+	}
+	return xc.changeProp32(xc.screen.Root, "_NET_CLIENT_LIST_STACKING", xproto.AtomWindow, vals...)
+}
+
+// HasWindowState reports whether the state is listed in the window's _NET_WM_STATE property
+func (xc *Connection) HasWindowState(win xproto.Window, state string) bool {
+	states, err := xc.getProps32(win, "_NET_WM_STATE")
+	if err != nil {
+		return false
+	}
+	for _, s := range states {
+		if xproto.Atom(s) == xc.Atom(state) {
+			return true
+		}
+	}
+	return false
+}
+
 func (xc *Connection) SetDesktopHints(names []string, index int, windows []xproto.Window) error {
 	var err error
 	err = xc.SetNumberOfDesktops(len(names))
@@ -120,6 +217,72 @@ func (xc *Connection) SetWindowDesktop(win xproto.Window, desktop int) error {
 	return xc.changeProp32(win, "_NET_WM_DESKTOP", xproto.AtomCardinal, uint32(desktop))
 }
 
+// GetWindowPID returns the process ID stored in the window's _NET_WM_PID property
+func (xc *Connection) GetWindowPID(win xproto.Window) (int, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_PID")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property _NET_WM_PID on window %d", win)
+	}
+	return int(vals[0]), nil
+}
+
+// GetWindowDesktop returns the index of the desktop stored in the window's _NET_WM_DESKTOP property
+func (xc *Connection) GetWindowDesktop(win xproto.Window) (int, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_DESKTOP")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property _NET_WM_DESKTOP on window %d", win)
+	}
+	return int(vals[0]), nil
+}
+
+// GetUserTime returns the time of the last user activity in the window, stored in its _NET_WM_USER_TIME property
+func (xc *Connection) GetUserTime(win xproto.Window) (xproto.Timestamp, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_USER_TIME")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property _NET_WM_USER_TIME on window %d", win)
+	}
+	return xproto.Timestamp(vals[0]), nil
+}
+
+// GetUserTimeWindow returns the window holding the _NET_WM_USER_TIME property on behalf of the given window
+func (xc *Connection) GetUserTimeWindow(win xproto.Window) (xproto.Window, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_USER_TIME_WINDOW")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property _NET_WM_USER_TIME_WINDOW on window %d", win)
+	}
+	return xproto.Window(vals[0]), nil
+}
+
+// GetStartupID returns the startup notification ID stored in the window's _NET_STARTUP_ID property
+func (xc *Connection) GetStartupID(win xproto.Window) (string, error) {
+	reply, err := xc.getProp(win, "_NET_STARTUP_ID")
+	if err != nil {
+		return "", err
+	}
+	return string(reply.Value), nil
+}
+
+// SetWindowState replaces the window's _NET_WM_STATE property with the given list of state atoms
+func (xc *Connection) SetWindowState(win xproto.Window, states []string) error {
+	vals := make([]uint32, len(states))
+	for i, state := range states {
+		vals[i] = uint32(xc.Atom(state))
+	}
+	return xc.changeProp32(win, "_NET_WM_STATE", xproto.AtomAtom, vals...)
+}
+
 func (xc *Connection) setHints() error {
 	atoms := make([]uint32, len(ewmhSupported))
 	for i, s := range ewmhSupported {
@@ -0,0 +1,71 @@
+package x11
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// GetWindowClass returns the instance and class names stored in the window's WM_CLASS property
+func (xc *Connection) GetWindowClass(win xproto.Window) (instance, class string, err error) {
+	reply, err := xc.getProp(win, "WM_CLASS")
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.Split(strings.TrimRight(string(reply.Value), "\x00"), "\x00")
+	instance = parts[0]
+	if len(parts) > 1 {
+		class = parts[1]
+	}
+	return instance, class, nil
+}
+
+// GetWindowRole returns the value of the window's WM_WINDOW_ROLE property
+func (xc *Connection) GetWindowRole(win xproto.Window) (string, error) {
+	reply, err := xc.getProp(win, "WM_WINDOW_ROLE")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(reply.Value), "\x00"), nil
+}
+
+// GetTransientFor returns the window stored in the window's WM_TRANSIENT_FOR property
+func (xc *Connection) GetTransientFor(win xproto.Window) (xproto.Window, error) {
+	vals, err := xc.getProps32(win, "WM_TRANSIENT_FOR")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property WM_TRANSIENT_FOR on window %d", win)
+	}
+	return xproto.Window(vals[0]), nil
+}
+
+// GetNormalHints returns the raw values of the WM_SIZE_HINTS structure stored in the window's WM_NORMAL_HINTS
+// property
+func (xc *Connection) GetNormalHints(win xproto.Window) ([]uint32, error) {
+	return xc.getProps32(win, "WM_NORMAL_HINTS")
+}
+
+// GetWMHints returns the raw values of the WM_HINTS structure stored in the window's WM_HINTS property
+func (xc *Connection) GetWMHints(win xproto.Window) ([]uint32, error) {
+	return xc.getProps32(win, "WM_HINTS")
+}
+
+// SetWMState sets the window's WM_STATE property to the given state, with no icon window
+func (xc *Connection) SetWMState(win xproto.Window, state uint32) error {
+	return xc.changeProp32(win, "WM_STATE", xc.Atom("WM_STATE"), state, 0)
+}
+
+// GetWMState returns the state stored in the window's WM_STATE property
+func (xc *Connection) GetWMState(win xproto.Window) (uint32, error) {
+	vals, err := xc.getProps32(win, "WM_STATE")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty property WM_STATE on window %d", win)
+	}
+	return vals[0], nil
+}
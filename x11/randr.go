@@ -0,0 +1,70 @@
+package x11
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/randr"
+)
+
+// Output describes a RandR output (monitor) that is connected and enabled
+type Output struct {
+	Name    string
+	X, Y    int16
+	W, H    uint16
+	Primary bool
+}
+
+// InitRandR initializes the RandR extension and selects the events notifying about changes of the outputs
+func (xc *Connection) InitRandR() error {
+	if err := randr.Init(xc.conn); err != nil {
+		return fmt.Errorf("failed to initialize RandR: %v", err)
+	}
+	err := randr.SelectInputChecked(xc.conn, xc.GetRootWindow(), randr.NotifyMaskScreenChange).Check()
+	if err != nil {
+		return fmt.Errorf("failed to select RandR events: %v", err)
+	}
+	return nil
+}
+
+// GetOutputs returns the enabled outputs, with the primary output first. Outputs cloning the contents
+// of another output are omitted
+func (xc *Connection) GetOutputs() ([]Output, error) {
+	res, err := randr.GetScreenResourcesCurrent(xc.conn, xc.GetRootWindow()).Reply()
+	if err != nil {
+		return nil, err
+	}
+	var primary randr.Output
+	if reply, err := randr.GetOutputPrimary(xc.conn, xc.GetRootWindow()).Reply(); err == nil {
+		primary = reply.Output
+	}
+	var outputs []Output
+	crtcs := make(map[randr.Crtc]bool)
+	for _, id := range res.Outputs {
+		info, err := randr.GetOutputInfo(xc.conn, id, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, err
+		}
+		if info.Connection != randr.ConnectionConnected || info.Crtc == 0 || crtcs[info.Crtc] {
+			continue
+		}
+		crtcs[info.Crtc] = true
+		crtc, err := randr.GetCrtcInfo(xc.conn, info.Crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, err
+		}
+		out := Output{
+			Name:    string(info.Name),
+			X:       crtc.X,
+			Y:       crtc.Y,
+			W:       crtc.Width,
+			H:       crtc.Height,
+			Primary: id == primary,
+		}
+		if out.Primary {
+			outputs = append([]Output{out}, outputs...)
+		} else {
+			outputs = append(outputs, out)
+		}
+	}
+	return outputs, nil
+}
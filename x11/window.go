@@ -60,6 +60,12 @@ func (xc *Connection) CreateWindow(parent xproto.Window, x, y int16, width, heig
 	return id, nil
 }
 
+// WindowMapped reports whether the window is currently mapped
+func (xc *Connection) WindowMapped(window xproto.Window) bool {
+	attrs, err := xproto.GetWindowAttributes(xc.conn, window).Reply()
+	return err == nil && attrs.MapState != xproto.MapStateUnmapped
+}
+
 func (xc *Connection) MapWindow(window xproto.Window) error {
 	return xproto.MapWindowChecked(xc.conn, window).Check()
 }
@@ -75,3 +81,9 @@ func (xc *Connection) DestroyWindow(window xproto.Window) error {
 func (xc *Connection) ReparentWindow(window, parent xproto.Window, x, y int16) error {
 	return xproto.ReparentWindowChecked(xc.conn, window, parent, x, y).Check()
 }
+
+// AddToSaveSet makes sure that the window survives the WM exiting, by having the X server reparent
+// it back to the root window
+func (xc *Connection) AddToSaveSet(window xproto.Window) error {
+	return xproto.ChangeSaveSetChecked(xc.conn, xproto.SetModeInsert, window).Check()
+}
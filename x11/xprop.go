@@ -45,3 +45,51 @@ func (xc *Connection) changeProp(win xproto.Window, format byte, prop string, ty
 	return xproto.ChangePropertyChecked(xc.conn, xproto.PropModeReplace, win, propAtom, typ, format,
 		uint32(len(data)/(int(format)/8)), data).Check()
 }
+
+// GetRootProp returns the entire value of the root window's property of the given name
+func (xc *Connection) GetRootProp(name string) ([]byte, error) {
+	atom := xc.Atom(name)
+	reply, err := xproto.GetProperty(xc.conn, false, xc.screen.Root, atom, xproto.GetPropertyTypeAny, 0, 1<<24).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving property %q on the root window: %v", name, err)
+	}
+	if reply == nil || reply.Format == 0 {
+		return nil, fmt.Errorf("no such property %q on the root window", name)
+	}
+	return reply.Value, nil
+}
+
+// PopRootProp returns the entire value of the root window's property of the given name, deleting
+// the property at the same time
+func (xc *Connection) PopRootProp(name string) ([]byte, error) {
+	atom := xc.Atom(name)
+	reply, err := xproto.GetProperty(xc.conn, true, xc.screen.Root, atom, xproto.GetPropertyTypeAny, 0, 1<<24).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving property %q on the root window: %v", name, err)
+	}
+	if reply == nil || reply.Format == 0 {
+		return nil, fmt.Errorf("no such property %q on the root window", name)
+	}
+	return reply.Value, nil
+}
+
+// AppendRootProp appends the UTF-8 string value to the root window's property of the given name
+func (xc *Connection) AppendRootProp(name string, value []byte) error {
+	return xproto.ChangePropertyChecked(xc.conn, xproto.PropModeAppend, xc.screen.Root, xc.Atom(name),
+		xc.Atom("UTF8_STRING"), 8, uint32(len(value)), value).Check()
+}
+
+// SetRootProp sets the root window's property of the given name to a UTF-8 string value
+func (xc *Connection) SetRootProp(name string, value []byte) error {
+	return xc.changeProp(xc.screen.Root, 8, name, xc.Atom("UTF8_STRING"), value)
+}
+
+// SetRootCardinals sets the root window's property of the given name to a list of CARDINAL values
+func (xc *Connection) SetRootCardinals(name string, values []uint32) error {
+	return xc.changeProp32(xc.screen.Root, name, xproto.AtomCardinal, values...)
+}
+
+// DeleteRootProp removes the root window's property of the given name
+func (xc *Connection) DeleteRootProp(name string) error {
+	return xproto.DeletePropertyChecked(xc.conn, xc.screen.Root, xc.Atom(name)).Check()
+}